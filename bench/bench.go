@@ -0,0 +1,321 @@
+// Package bench runs the same workloads on the closure based linq.Seq,
+// the channel based yield/linq.Iter and a hand-written loop, see bench_test.go.
+//
+// The sub-benchmarks are named workload/impl=loop|seq|iter, so the output
+// goes straight into benchstat, -col /impl compares the implementations,
+// and two runs compare for regressions:
+//
+//	go test ./bench -run NONE -bench . -count 6 | tee new.txt | go run ./cmd/benchtable
+//	benchstat -col /impl new.txt
+//	benchstat old.txt new.txt
+//
+// Table is the first one for machines without benchstat.
+package bench
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ↓↓↓↓↓↓ Parse ↓↓↓↓↓↓
+
+// Result holds the samples of a benchmark by unit, e.g. sec/op, B/op, allocs/op
+type Result struct {
+	Name    string // without the impl key and the GOMAXPROCS suffix
+	Impl    string
+	Samples map[string][]float64
+}
+
+// Results of go test -bench, in the order of first appearance
+type Results struct {
+	Config []string // goos, goarch, pkg, cpu
+	Names  []string
+	Impls  []string
+	byKey  map[[2]string]*Result
+}
+
+func (rs *Results) Get(name, impl string) *Result { return rs.byKey[[2]string{name, impl}] }
+
+var procs = regexp.MustCompile(`-\d+$`)
+
+// Parse reads the output of go test -bench, -count adds samples
+func Parse(r io.Reader) (*Results, error) {
+	rs := &Results{byKey: map[[2]string]*Result{}}
+	seen := map[string]bool{}
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if k, _, ok := strings.Cut(line, ":"); ok && !strings.Contains(k, " ") && !strings.HasPrefix(k, "Benchmark") {
+			if !seen[k] && (k == "goos" || k == "goarch" || k == "pkg" || k == "cpu") {
+				seen[k] = true
+				rs.Config = append(rs.Config, line)
+			}
+			continue
+		}
+		fs := strings.Fields(line)
+		if len(fs) < 4 || !strings.HasPrefix(fs[0], "Benchmark") || len(fs)%2 != 0 {
+			continue
+		}
+		if _, err := strconv.Atoi(fs[1]); err != nil {
+			continue
+		}
+
+		var parts []string
+		impl := ""
+		for _, p := range strings.Split(procs.ReplaceAllString(fs[0][len("Benchmark"):], ""), "/") {
+			if v, ok := strings.CutPrefix(p, "impl="); ok {
+				impl = v
+			} else {
+				parts = append(parts, p)
+			}
+		}
+		name := strings.Join(parts, "/")
+		res := rs.Get(name, impl)
+		if res == nil {
+			res = &Result{Name: name, Impl: impl, Samples: map[string][]float64{}}
+			rs.byKey[[2]string{name, impl}] = res
+			rs.Names = appendNew(rs.Names, name)
+			rs.Impls = appendNew(rs.Impls, impl)
+		}
+		for i := 2; i < len(fs); i += 2 {
+			v, err := strconv.ParseFloat(fs[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("bench: %s: %w", line, err)
+			}
+			unit := fs[i+1]
+			if unit == "ns/op" {
+				unit, v = "sec/op", v/1e9
+			}
+			res.Samples[unit] = append(res.Samples[unit], v)
+		}
+	}
+	return rs, sc.Err()
+}
+
+func appendNew(xs []string, x string) []string {
+	for _, y := range xs {
+		if y == x {
+			return xs
+		}
+	}
+	return append(xs, x)
+}
+
+// ↓↓↓↓↓↓ Table ↓↓↓↓↓↓
+
+var units = []string{"sec/op", "B/op", "allocs/op"}
+
+// Table writes the results in the layout of benchstat -col /impl,
+// the first impl is the base, ± is the half range of the samples
+// instead of a confidence interval, and there is no p-value
+func Table(w io.Writer, rs *Results) error {
+	var b strings.Builder
+	for _, c := range rs.Config {
+		b.WriteString(c + "\n")
+	}
+	footnote := false
+	for _, unit := range units {
+		if !rs.has(unit) {
+			continue
+		}
+		rows := [][]string{
+			append([]string{""}, rs.Impls...),
+			{""},
+		}
+		for range rs.Impls {
+			rows[1] = append(rows[1], unit)
+		}
+		geo, positive := make([][]float64, len(rs.Impls)), true
+		for _, name := range rs.Names {
+			row := []string{name}
+			base := 0.0
+			for i, impl := range rs.Impls {
+				res := rs.Get(name, impl)
+				if res == nil || len(res.Samples[unit]) == 0 {
+					row = append(row, "")
+					continue
+				}
+				xs := res.Samples[unit]
+				m := mean(xs)
+				cell := format(m, unit) + " ± " + spread(xs, m)
+				if i == 0 {
+					base = m
+				} else {
+					cell += "  " + delta(base, m)
+				}
+				row = append(row, cell)
+			}
+			rows = append(rows, row)
+			// the geomean of the workloads run by every impl
+			if ms, ok := rs.means(name, unit); ok {
+				for i, m := range ms {
+					geo[i] = append(geo[i], m)
+					positive = positive && m > 0
+				}
+			}
+		}
+		switch {
+		case len(geo[0]) < 2:
+		case !positive:
+			rows = append(rows, []string{"geomean", "¹"})
+			footnote = true
+		default:
+			row := []string{"geomean"}
+			base := geomean(geo[0])
+			for i := range rs.Impls {
+				g := geomean(geo[i])
+				cell := format(g, unit)
+				if i > 0 {
+					cell += "  " + delta(base, g)
+				}
+				row = append(row, cell)
+			}
+			rows = append(rows, row)
+		}
+		b.WriteString("\n")
+		writeRows(&b, rows)
+	}
+	if footnote {
+		b.WriteString("¹ summaries must be >0 to compute geomean\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// means of name by impl, false if an impl misses it
+func (rs *Results) means(name, unit string) ([]float64, bool) {
+	ms := make([]float64, len(rs.Impls))
+	for i, impl := range rs.Impls {
+		res := rs.Get(name, impl)
+		if res == nil || len(res.Samples[unit]) == 0 {
+			return nil, false
+		}
+		ms[i] = mean(res.Samples[unit])
+	}
+	return ms, true
+}
+
+func (rs *Results) has(unit string) bool {
+	for _, r := range rs.byKey {
+		if len(r.Samples[unit]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// writeRows aligns the columns, the first two rows are the header
+func writeRows(b *strings.Builder, rows [][]string) {
+	width := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, c := range row {
+			width[i] = max(width[i], utf8.RuneCountInString(c))
+		}
+	}
+	pad := func(s string, n int) string { return s + strings.Repeat(" ", n-utf8.RuneCountInString(s)) }
+	center := func(s string, n int) string {
+		l := (n - utf8.RuneCountInString(s)) / 2
+		return pad(strings.Repeat(" ", l)+s, n)
+	}
+	for r, row := range rows {
+		line := pad(row[0], width[0])
+		for i := 1; i < len(width); i++ {
+			c := ""
+			if i < len(row) {
+				c = row[i]
+			}
+			if r < 2 {
+				line += " │ " + center(c, width[i])
+			} else {
+				line += "   " + pad(c, width[i])
+			}
+		}
+		if r < 2 {
+			line += " │"
+		}
+		b.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+}
+
+// ↓↓↓↓↓↓ Stats ↓↓↓↓↓↓
+
+func mean(xs []float64) float64 {
+	s := 0.0
+	for _, x := range xs {
+		s += x
+	}
+	return s / float64(len(xs))
+}
+
+func geomean(xs []float64) float64 {
+	s := 0.0
+	for _, x := range xs {
+		s += math.Log(x)
+	}
+	return math.Exp(s / float64(len(xs)))
+}
+
+func spread(xs []float64, m float64) string {
+	if len(xs) < 2 {
+		return "∞"
+	}
+	lo, hi := xs[0], xs[0]
+	for _, x := range xs {
+		lo, hi = min(lo, x), max(hi, x)
+	}
+	if m == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.0f%%", (hi-lo)/2/m*100)
+}
+
+func delta(base, x float64) string {
+	switch {
+	case base == x:
+		return "~"
+	case base == 0:
+		return "+∞"
+	}
+	return fmt.Sprintf("%+.2f%%", (x-base)/base*100)
+}
+
+// format scales v with the SI prefixes of benchstat, binary ones for B/op
+func format(v float64, unit string) string {
+	prefixes, step := []string{"", "k", "M", "G"}, 1000.0
+	switch unit {
+	case "sec/op":
+		if v < 1 {
+			for _, p := range []string{"m", "µ", "n"} {
+				if v *= 1000; v >= 1 {
+					return sig4(v) + p
+				}
+			}
+			return sig4(v) + "n"
+		}
+	case "B/op":
+		prefixes, step = []string{"", "Ki", "Mi", "Gi"}, 1024
+	}
+	i := 0
+	for ; v >= step && i < len(prefixes)-1; i++ {
+		v /= step
+	}
+	return sig4(v) + prefixes[i]
+}
+
+func sig4(v float64) string {
+	switch {
+	case v == 0 || v >= 1000:
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	case v >= 100:
+		return strconv.FormatFloat(v, 'f', 1, 64)
+	case v >= 10:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
@@ -0,0 +1,243 @@
+package bench
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goghcrow/go-linq-object"
+	ylinq "github.com/goghcrow/go-linq-object/yield/linq"
+)
+
+func assertEqual(t *testing.T, x, y any) {
+	if !reflect.DeepEqual(x, y) {
+		t.Fail()
+	}
+}
+
+func isEven(x int) bool { return x%2 == 0 }
+func isOdd(x int) bool  { return x%2 != 0 }
+func square(x int) int  { return x * x }
+func add(a, b int) int  { return a + b }
+
+// counter is an infinite source of the naturals
+func counter() func() (int, bool) {
+	i := 0
+	return func() (int, bool) {
+		i++
+		return i - 1, true
+	}
+}
+
+// sink keeps the results alive
+var sink any
+
+// ↓↓↓↓↓↓ Workloads ↓↓↓↓↓↓
+
+const (
+	size      = 10_000
+	takeCount = 1_000
+	crossSize = 100
+	sliceSize = 100_000
+)
+
+func BenchmarkFilterMapReduce(b *testing.B) {
+	b.Run("impl=loop", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			sum := 0
+			for i := 0; i < size; i++ {
+				if isEven(i) {
+					sum += square(i)
+				}
+			}
+			sink = sum
+		}
+	})
+	b.Run("impl=seq", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			sink = linq.Fold(linq.Select(linq.Where(linq.Range(0, size), isEven), square), 0, add)
+		}
+	})
+	b.Run("impl=iter", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			sink = ylinq.Fold(ylinq.Select(ylinq.Where(ylinq.Range(0, size), isEven), square), 0, add)
+		}
+	})
+}
+
+func BenchmarkTakeInfinite(b *testing.B) {
+	b.Run("impl=loop", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			next, sum := counter(), 0
+			for n := 0; n < takeCount; {
+				if x, _ := next(); isOdd(x) {
+					sum, n = sum+x, n+1
+				}
+			}
+			sink = sum
+		}
+	})
+	b.Run("impl=seq", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			xs := linq.Take(linq.Where(linq.SeqOf[int](counter()), isOdd), takeCount)
+			sink = linq.Fold(xs, 0, add)
+		}
+	})
+	b.Run("impl=iter", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			// the producers run ahead of Take until cancel
+			ctx, cancel := context.WithCancel(context.Background())
+			xs := ylinq.TakeContext(ctx, ylinq.WhereContext(ctx, ylinq.FromContext(ctx, counter()), isOdd), takeCount)
+			sink = ylinq.Fold(xs, 0, add)
+			cancel()
+		}
+	})
+}
+
+func BenchmarkCrossJoin(b *testing.B) {
+	b.Run("impl=loop", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			sum := 0
+			for x := 0; x < crossSize; x++ {
+				for y := 0; y < crossSize; y++ {
+					sum += x * y
+				}
+			}
+			sink = sum
+		}
+	})
+	b.Run("impl=seq", func(b *testing.B) {
+		b.ReportAllocs()
+		ys := linq.Range(0, crossSize)
+		for range b.N {
+			xs := linq.SelectMany(linq.Range(0, crossSize), func(x int) linq.Seq[int] {
+				return linq.Select(ys, func(y int) int { return x * y })
+			})
+			sink = linq.Fold(xs, 0, add)
+		}
+	})
+	b.Run("impl=iter", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			// an Iter is consumed once, so the inner one is created per x
+			xs := ylinq.SelectMany(ylinq.Range(0, crossSize), func(x int) ylinq.Iter[int] {
+				return ylinq.Select(ylinq.Range(0, crossSize), func(y int) int { return x * y })
+			})
+			sink = ylinq.Fold(xs, 0, add)
+		}
+	})
+}
+
+func BenchmarkToSlice(b *testing.B) {
+	b.Run("impl=loop", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			var xs []int
+			for i := 0; i < sliceSize; i++ {
+				xs = append(xs, i)
+			}
+			sink = xs
+		}
+	})
+	b.Run("impl=seq", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			sink = linq.ToSlice(linq.Range(0, sliceSize))
+		}
+	})
+	b.Run("impl=iter", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			sink = ylinq.Range(0, sliceSize).ToSlice()
+		}
+	})
+}
+
+// TestWorkloads checks the implementations of a workload agree
+func TestWorkloads(t *testing.T) {
+	{
+		want := 0
+		for i := 0; i < size; i += 2 {
+			want += i * i
+		}
+		assertEqual(t, linq.Fold(linq.Select(linq.Where(linq.Range(0, size), isEven), square), 0, add), want)
+		assertEqual(t, ylinq.Fold(ylinq.Select(ylinq.Where(ylinq.Range(0, size), isEven), square), 0, add), want)
+	}
+	{
+		want := takeCount * takeCount // the sum of the first n odd numbers
+		assertEqual(t, linq.Fold(linq.Take(linq.Where(linq.SeqOf[int](counter()), isOdd), takeCount), 0, add), want)
+		ctx, cancel := context.WithCancel(context.Background())
+		xs := ylinq.TakeContext(ctx, ylinq.WhereContext(ctx, ylinq.FromContext(ctx, counter()), isOdd), takeCount)
+		assertEqual(t, ylinq.Fold(xs, 0, add), want)
+		cancel()
+	}
+}
+
+// ↓↓↓↓↓↓ Table ↓↓↓↓↓↓
+
+const output = `goos: linux
+goarch: amd64
+pkg: github.com/goghcrow/go-linq-object/bench
+cpu: Intel(R) Xeon(R) Processor
+BenchmarkFilterMapReduce/impl=loop-8         	  200000	      8000 ns/op	       0 B/op	       0 allocs/op
+BenchmarkFilterMapReduce/impl=loop-8         	  200000	      8400 ns/op	       0 B/op	       0 allocs/op
+BenchmarkFilterMapReduce/impl=seq-8          	   10000	    160000 ns/op	     200 B/op	       6 allocs/op
+BenchmarkFilterMapReduce/impl=seq-8          	   10000	    164000 ns/op	     200 B/op	       6 allocs/op
+BenchmarkFilterMapReduce/impl=iter-8         	     500	   2000000 ns/op	    2048 B/op	      20 allocs/op
+BenchmarkFilterMapReduce/impl=iter-8         	     500	   2200000 ns/op	    2048 B/op	      20 allocs/op
+BenchmarkToSlice/impl=loop-8                 	    1000	   1000000 ns/op	 4194304 B/op	      30 allocs/op
+BenchmarkToSlice/impl=seq-8                  	    1000	   1500000 ns/op	 4194304 B/op	      32 allocs/op
+BenchmarkToSlice/impl=iter-8                 	      50	  30000000 ns/op	 8388608 B/op	  100030 allocs/op
+PASS
+ok  	github.com/goghcrow/go-linq-object/bench	10.0s
+`
+
+func TestTable(t *testing.T) {
+	rs, err := Parse(strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, rs.Names, []string{"FilterMapReduce", "ToSlice"})
+	assertEqual(t, rs.Impls, []string{"loop", "seq", "iter"})
+	assertEqual(t, rs.Get("FilterMapReduce", "seq").Samples["allocs/op"], []float64{6, 6})
+
+	var b strings.Builder
+	if err := Table(&b, rs); err != nil {
+		t.Fatal(err)
+	}
+	want := `goos: linux
+goarch: amd64
+pkg: github.com/goghcrow/go-linq-object/bench
+cpu: Intel(R) Xeon(R) Processor
+
+                │    loop     │          seq           │          iter           │
+                │   sec/op    │         sec/op         │         sec/op          │
+FilterMapReduce   8.200µ ± 2%   162.0µ ± 1%  +1875.61%   2.100m ± 5%  +25509.76%
+ToSlice           1.000m ± ∞    1.500m ± ∞  +50.00%      30.00m ± ∞  +2900.00%
+geomean           90.55µ        493.0µ  +444.37%         7.937m  +8665.23%
+
+                │    loop     │      seq       │         iter          │
+                │    B/op     │      B/op      │         B/op          │
+FilterMapReduce   0 ± 0%        200.0 ± 0%  +∞   2.000Ki ± 0%  +∞
+ToSlice           4.000Mi ± ∞   4.000Mi ± ∞  ~   8.000Mi ± ∞  +100.00%
+geomean           ¹
+
+                │   loop    │        seq        │          iter           │
+                │ allocs/op │     allocs/op     │        allocs/op        │
+FilterMapReduce   0 ± 0%      6.000 ± 0%  +∞      20.00 ± 0%  +∞
+ToSlice           30.00 ± ∞   32.00 ± ∞  +6.67%   100.0k ± ∞  +333333.33%
+geomean           ¹
+¹ summaries must be >0 to compute geomean
+`
+	if got := b.String(); got != want {
+		t.Errorf("got\n%s", got)
+	}
+}
@@ -0,0 +1,40 @@
+// Command benchtable prints the output of go test -bench, read from the files
+// or stdin, as a table comparing the impl= sub-benchmarks, see package bench.
+//
+//	go test ./bench -run NONE -bench . -count 6 | go run ./cmd/benchtable
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+
+	"github.com/goghcrow/go-linq-object/bench"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("benchtable: ")
+
+	var r io.Reader = os.Stdin
+	if len(os.Args) > 1 {
+		var rs []io.Reader
+		for _, name := range os.Args[1:] {
+			f, err := os.Open(name)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer f.Close()
+			rs = append(rs, f)
+		}
+		r = io.MultiReader(rs...)
+	}
+
+	rs, err := bench.Parse(r)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := bench.Table(os.Stdout, rs); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,578 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	linqPath = "github.com/goghcrow/go-linq-object"
+	marker   = "//linq:query "
+)
+
+// generate returns the generated file for filename, nil if there is no query
+func generate(filename string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkg, file, err := load(fset, filename)
+	if err != nil {
+		return nil, err
+	}
+	g, err := newGen(fset, pkg, file)
+	if err != nil {
+		return nil, err
+	}
+
+	var funcs []string
+	for _, cg := range file.Comments {
+		if !strings.HasPrefix(cg.List[0].Text, marker) {
+			continue
+		}
+		code, err := g.query(cg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fset.Position(cg.Pos()), err)
+		}
+		funcs = append(funcs, code)
+	}
+	if len(funcs) == 0 {
+		return nil, nil
+	}
+	return g.output(filepath.Base(filename), funcs)
+}
+
+// load type-checks the package of filename without its generated files,
+// errors are ignored, since the package may use the functions not generated yet
+func load(fset *token.FileSet, filename string) (*types.Package, *ast.File, error) {
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+	target, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	files := []*ast.File{target}
+	for _, e := range entries {
+		name := e.Name()
+		switch {
+		case name == base, e.IsDir(), !strings.HasSuffix(name, ".go"),
+			strings.HasSuffix(name, "_linq.go"), strings.HasSuffix(name, "_linq_test.go"),
+			strings.HasSuffix(name, "_test.go") && !strings.HasSuffix(base, "_test.go"):
+			continue
+		}
+		if ok, err := build.Default.MatchFile(dir, name); err != nil || !ok {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, nil, err
+		}
+		if f.Name.Name == target.Name.Name {
+			files = append(files, f)
+		}
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(error) {},
+	}
+	pkg, _ := conf.Check(target.Name.Name, fset, files, nil)
+	return pkg, target, nil
+}
+
+// ↓↓↓↓↓↓ Generator ↓↓↓↓↓↓
+
+type gen struct {
+	fset    *token.FileSet
+	pkg     *types.Package
+	file    *ast.File
+	imports map[string]string // path -> name in file
+	linq    *types.Package
+	params  string         // of the current query
+	tuples  []*types.Named // declared at the top of the current query
+}
+
+type rvar struct {
+	name string
+	typ  types.Type
+}
+
+// seq is a Go expression of a linq.Seq, its elements are the range variable vars[0],
+// or a tuple struct of vars
+type seq struct {
+	expr  string
+	elem  types.Type
+	vars  []rvar
+	tuple bool
+}
+
+// segment is a seq followed by from / where / let clauses, which nest into Bind,
+// orderby / join / group flush it into a seq of tuples
+type segment struct {
+	src  seq
+	body []step
+	env  []rvar
+}
+
+type step struct {
+	clause
+	typ types.Type
+}
+
+func newGen(fset *token.FileSet, pkg *types.Package, file *ast.File) (*gen, error) {
+	g := &gen{fset: fset, pkg: pkg, file: file, imports: map[string]string{}}
+	byPath := map[string]*types.Package{}
+	for _, p := range pkg.Imports() {
+		byPath[p.Path()] = p
+	}
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		switch {
+		case spec.Name != nil:
+			g.imports[path] = spec.Name.Name
+		case byPath[path] != nil:
+			g.imports[path] = byPath[path].Name()
+		}
+	}
+	if _, ok := g.imports[linqPath]; ok {
+		g.linq = byPath[linqPath]
+	} else if pkg.Scope().Lookup("Bind") != nil && pkg.Scope().Lookup("Seq") != nil {
+		g.linq = pkg // inside the linq package itself
+	} else {
+		return nil, fmt.Errorf("%s must import %s", fset.Position(file.Pos()).Filename, linqPath)
+	}
+	return g, nil
+}
+
+// fn qualifies a function of the linq package
+func (g *gen) fn(name string) string {
+	if g.linq == g.pkg {
+		return name
+	}
+	return g.imports[linqPath] + "." + name
+}
+
+func (g *gen) typ(t types.Type) (string, error) {
+	var err error
+	s := types.TypeString(t, func(p *types.Package) string {
+		if p == g.pkg {
+			return ""
+		}
+		name, ok := g.imports[p.Path()]
+		if !ok && err == nil {
+			err = fmt.Errorf("%s: file must import %s", t, p.Path())
+		}
+		return name
+	})
+	return s, err
+}
+
+func (g *gen) mustTyp(t types.Type) string {
+	s, err := g.typ(t)
+	if err != nil {
+		panic(genError{err})
+	}
+	return s
+}
+
+type genError struct{ error }
+
+// query translates a marked comment into a function
+func (g *gen) query(cg *ast.CommentGroup) (code string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(genError)
+			if !ok {
+				panic(r)
+			}
+			err = e.error
+		}
+	}()
+
+	sig := strings.TrimSpace(strings.TrimPrefix(cg.List[0].Text, marker))
+	open, end := strings.IndexByte(sig, '('), strings.LastIndexByte(sig, ')')
+	if open <= 0 || end != len(sig)-1 || !token.IsIdentifier(sig[:open]) {
+		return "", fmt.Errorf("expect %sname(params), the result type is inferred", marker)
+	}
+	name := sig[:open]
+	g.params, g.tuples = sig[open+1:end], nil
+
+	var lines []string
+	for _, c := range cg.List[1:] {
+		if !strings.HasPrefix(c.Text, "//") {
+			return "", fmt.Errorf("query must be in // comments")
+		}
+		lines = append(lines, strings.TrimPrefix(c.Text, "//"))
+	}
+	cs, err := parseQuery(strings.Join(lines, "\n"))
+	if err != nil {
+		return "", err
+	}
+
+	expr, r := g.translate(cs)
+	var b strings.Builder
+	fmt.Fprintf(&b, "func %s(%s) %s {\n", name, g.params, g.seqOf(r))
+	for _, t := range g.tuples {
+		fmt.Fprintf(&b, "type %s %s\n", t.Obj().Name(), g.mustTyp(t.Underlying()))
+	}
+	fmt.Fprintf(&b, "return %s\n}\n", expr)
+	return b.String(), nil
+}
+
+func (g *gen) fail(format string, args ...any) {
+	panic(genError{fmt.Errorf(format, args...)})
+}
+
+func (g *gen) translate(cs []clause) (expr string, r types.Type) {
+	first := cs[0]
+	elem := g.elemOf(first.exprs[0], nil)
+	seg := segment{
+		src: seq{expr: first.exprs[0], elem: elem, vars: []rvar{{first.name, elem}}},
+		env: []rvar{{first.name, elem}},
+	}
+
+	for i, c := range cs[1:] {
+		last := i == len(cs)-2
+		switch c.kind {
+		case "from":
+			t := g.elemOf(c.exprs[0], seg.env)
+			seg.body = append(seg.body, step{c, t})
+			seg.env = g.bind(seg.env, rvar{c.name, t})
+		case "where":
+			g.typeOf(c.exprs[0], seg.env)
+			seg.body = append(seg.body, step{c, nil})
+		case "let":
+			t := g.typeOf(c.exprs[0], seg.env)
+			seg.body = append(seg.body, step{c, t})
+			seg.env = g.bind(seg.env, rvar{c.name, t})
+		case "orderby":
+			seg = g.orderBy(seg, c)
+		case "join":
+			seg = g.join(seg, c)
+		case "select", "group":
+			if c.kind == "select" {
+				r = g.typeOf(c.exprs[0], seg.env)
+				expr = g.nest(seg, c.exprs[0], r)
+			} else {
+				expr, r = g.groupBy(seg, c)
+			}
+			if c.into != "" {
+				v := rvar{c.into, r}
+				seg = segment{src: seq{expr: expr, elem: r, vars: []rvar{v}}, env: []rvar{v}}
+				if last {
+					g.fail("query must end with select or group")
+				}
+				continue
+			}
+			if !last {
+				g.fail("%s must end the query, continue with into", c.kind)
+			}
+			return
+		}
+	}
+	g.fail("query must end with select or group")
+	return
+}
+
+func (g *gen) bind(env []rvar, v rvar) []rvar {
+	for _, u := range env {
+		if u.name == v.name {
+			g.fail("range variable %s redeclared", v.name)
+		}
+	}
+	return append(slices.Clip(env), v)
+}
+
+// typeOf type-checks expr in the scope of the params and range variables
+func (g *gen) typeOf(expr string, env []rvar) types.Type {
+	params := []string{}
+	if strings.TrimSpace(g.params) != "" {
+		params = append(params, g.params)
+	}
+	for _, v := range env {
+		params = append(params, v.name+" "+g.mustTyp(v.typ))
+	}
+	// parsed into g.fset, so the positions don't overlap with the file,
+	// CheckExpr records the scope of the func literal in the file scope
+	src := fmt.Sprintf("func(%s) { _ = (%s) }", strings.Join(params, ", "), expr)
+	e, err := parser.ParseExprFrom(g.fset, "", src, 0)
+	if err != nil {
+		g.fail("%s: %v", expr, err)
+	}
+	info := &types.Info{Types: map[ast.Expr]types.TypeAndValue{}}
+	if err := types.CheckExpr(g.fset, g.pkg, g.file.Name.Pos(), e, info); err != nil {
+		g.fail("%s: %v", expr, err)
+	}
+	x := e.(*ast.FuncLit).Body.List[0].(*ast.AssignStmt).Rhs[0].(*ast.ParenExpr).X
+	tv := info.Types[x]
+	if !tv.IsValue() {
+		g.fail("%s is not a value", expr)
+	}
+	return types.Default(tv.Type)
+}
+
+// elemOf returns T of the source expr, which has a method Next() (T, bool)
+func (g *gen) elemOf(expr string, env []rvar) types.Type {
+	t := g.typeOf(expr, env)
+	obj, _, _ := types.LookupFieldOrMethod(t, true, g.pkg, "Next")
+	if fn, ok := obj.(*types.Func); ok {
+		res := fn.Type().(*types.Signature).Results()
+		if res.Len() == 2 && types.Identical(res.At(1).Type(), types.Typ[types.Bool]) {
+			return res.At(0).Type()
+		}
+	}
+	g.fail("%s is %s, not a %s.Seq", expr, t, g.linq.Name())
+	return nil
+}
+
+func (g *gen) instance(name string, args ...types.Type) types.Type {
+	t, err := types.Instantiate(nil, g.linq.Scope().Lookup(name).Type(), args, true)
+	if err != nil {
+		g.fail("%v", err)
+	}
+	return t
+}
+
+func (g *gen) seqOf(t types.Type) string { return g.mustTyp(g.instance("Seq", t)) }
+
+// ↓↓↓↓↓↓ Emit ↓↓↓↓↓↓
+
+// lambda binds the range variables of an element of s, which are used in uses
+func (g *gen) lambda(s seq, extra, result, body string, uses ...string) string {
+	var b strings.Builder
+	if s.tuple {
+		fmt.Fprintf(&b, "func(_t %s%s) %s {\n", g.mustTyp(s.elem), extra, result)
+		var names, fields []string
+		for _, v := range s.vars {
+			if used(v.name, uses...) {
+				names, fields = append(names, v.name), append(fields, "_t."+v.name)
+			}
+		}
+		if len(names) > 0 {
+			fmt.Fprintf(&b, "%s := %s\n", strings.Join(names, ", "), strings.Join(fields, ", "))
+		}
+	} else {
+		fmt.Fprintf(&b, "func(%s %s%s) %s {\n", s.vars[0].name, g.mustTyp(s.elem), extra, result)
+	}
+	b.WriteString(body)
+	b.WriteString("}")
+	return b.String()
+}
+
+func (g *gen) selector(s seq, r types.Type, expr string) string {
+	return g.lambda(s, "", g.mustTyp(r), "return "+expr+"\n", expr)
+}
+
+// yields reports whether yielding expr is the identity of s
+func (g *gen) yields(s seq, expr string) bool {
+	if !s.tuple {
+		return expr == s.vars[0].name
+	}
+	return expr == g.tupleLit(s.elem, s.vars)
+}
+
+// nest translates the segment into nested Bind, which returns yield at the innermost
+func (g *gen) nest(seg segment, yield string, r types.Type) string {
+	if len(seg.body) == 0 && g.yields(seg.src, yield) {
+		return seg.src.expr
+	}
+	uses := []string{yield}
+	for _, st := range seg.body {
+		uses = append(uses, st.exprs...)
+	}
+	body := g.stmts(seg.body, yield, r)
+	return fmt.Sprintf("%s(%s, %s)", g.fn("Bind"), seg.src.expr, g.lambda(seg.src, "", g.seqOf(r), body, uses...))
+}
+
+func (g *gen) stmts(body []step, yield string, r types.Type) string {
+	var b strings.Builder
+	for i, st := range body {
+		switch st.kind {
+		case "where":
+			fmt.Fprintf(&b, "if !(%s) {\nreturn %s[%s]()\n}\n", st.exprs[0], g.fn("From"), g.mustTyp(r))
+		case "let":
+			fmt.Fprintf(&b, "%s := %s\n", st.name, st.exprs[0])
+			uses := []string{yield}
+			for _, st := range body[i+1:] {
+				uses = append(uses, st.exprs...)
+			}
+			if !used(st.name, uses...) {
+				fmt.Fprintf(&b, "_ = %s\n", st.name)
+			}
+		case "from":
+			s := seq{expr: st.exprs[0], elem: st.typ, vars: []rvar{{st.name, st.typ}}}
+			fmt.Fprintf(&b, "return %s(%s, %s)\n", g.fn("Bind"), s.expr, g.lambda(s, "", g.seqOf(r), g.stmts(body[i+1:], yield, r)))
+			return b.String()
+		}
+	}
+	fmt.Fprintf(&b, "return %s(%s)\n", g.fn("Return"), yield)
+	return b.String()
+}
+
+// tuple declares a struct of the range variables, aka transparent identifier
+func (g *gen) tuple(vars []rvar) types.Type {
+	fields := make([]*types.Var, len(vars))
+	for i, v := range vars {
+		fields[i] = types.NewField(token.NoPos, g.pkg, v.name, v.typ, false)
+	}
+	name := types.NewTypeName(token.NoPos, g.pkg, fmt.Sprintf("tuple%d", len(g.tuples)+1), nil)
+	t := types.NewNamed(name, types.NewStruct(fields, nil), nil)
+	g.tuples = append(g.tuples, t)
+	return t
+}
+
+func (g *gen) tupleLit(t types.Type, vars []rvar) string {
+	names := make([]string, len(vars))
+	for i, v := range vars {
+		names[i] = v.name
+	}
+	return g.mustTyp(t) + "{" + strings.Join(names, ", ") + "}"
+}
+
+// flush turns the segment into a seq of its range variables
+func (g *gen) flush(seg segment) seq {
+	if len(seg.body) == 0 {
+		return seg.src
+	}
+	if len(seg.env) == 1 {
+		v := seg.env[0]
+		return seq{expr: g.nest(seg, v.name, v.typ), elem: v.typ, vars: seg.env}
+	}
+	t := g.tuple(seg.env)
+	return seq{expr: g.nest(seg, g.tupleLit(t, seg.env), t), elem: t, vars: seg.env, tuple: true}
+}
+
+func (g *gen) orderBy(seg segment, c clause) segment {
+	s := g.flush(seg)
+	for i, key := range c.exprs {
+		name := "OrderBy"
+		if i > 0 {
+			name = "ThenBy"
+		}
+		if c.desc[i] {
+			name += "Descending"
+		}
+		k := g.typeOf(key, seg.env)
+		s.expr = fmt.Sprintf("%s(%s, %s)", g.fn(name), s.expr, g.selector(s, k, key))
+	}
+	return segment{src: s, env: seg.env}
+}
+
+func (g *gen) join(seg segment, c clause) segment {
+	s := g.flush(seg)
+	src, outerKey, innerKey := c.exprs[0], c.exprs[1], c.exprs[2]
+	inner := g.elemOf(src, nil) // the inner source can't see the range variables
+	k := g.typeOf(outerKey, seg.env)
+	g.typeOf(innerKey, []rvar{{c.name, inner}})
+
+	name, v := "Join", rvar{c.name, inner}
+	if c.into != "" {
+		name, v = "GroupJoin", rvar{c.into, g.instance("Seq", inner)}
+	}
+	env := g.bind(seg.env, v)
+	t := g.tuple(env)
+	lit := g.tupleLit(t, env)
+
+	result := g.lambda(s, ", "+v.name+" "+g.mustTyp(v.typ), g.mustTyp(t), "return "+lit+"\n", lit)
+	innerSel := g.selector(seq{elem: inner, vars: []rvar{{c.name, inner}}}, k, innerKey)
+	expr := fmt.Sprintf("%s(%s, %s, %s, %s, %s)", g.fn(name), s.expr, src, g.selector(s, k, outerKey), innerSel, result)
+	return segment{src: seq{expr: expr, elem: t, vars: env, tuple: true}, env: env}
+}
+
+func (g *gen) groupBy(seg segment, c clause) (string, types.Type) {
+	s := g.flush(seg)
+	val, key := c.exprs[0], c.exprs[1]
+	k, e := g.typeOf(key, seg.env), g.typeOf(val, seg.env)
+	r := g.instance("Grouping", k, e)
+	if g.yields(s, val) {
+		return fmt.Sprintf("%s(%s, %s)", g.fn("GroupBy"), s.expr, g.selector(s, k, key)), r
+	}
+	return fmt.Sprintf("%s(%s, %s, %s)", g.fn("GroupByWithElement"), s.expr, g.selector(s, k, key), g.selector(s, e, val)), r
+}
+
+// used reports whether name occurs in exprs as an identifier, except as a field selector
+func used(name string, exprs ...string) bool {
+	for _, expr := range exprs {
+		e, err := parser.ParseExpr(expr)
+		if err != nil {
+			return true
+		}
+		found := false
+		ast.Inspect(e, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.SelectorExpr:
+				ast.Inspect(n.X, func(n ast.Node) bool {
+					if id, ok := n.(*ast.Ident); ok && id.Name == name {
+						found = true
+					}
+					return !found
+				})
+				return false
+			case *ast.Ident:
+				found = found || n.Name == name
+			}
+			return !found
+		})
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// file assembles the generated file, keeping the imports it uses
+func (g *gen) output(source string, funcs []string) ([]byte, error) {
+	code := strings.Join(funcs, "\n")
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+code, 0)
+	if err != nil {
+		return nil, fmt.Errorf("generated code: %v", err)
+	}
+	names := map[string]bool{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				names[id.Name] = true
+			}
+		}
+		return true
+	})
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by linqgen from %s; DO NOT EDIT.\n\npackage %s\n\n", source, g.file.Name.Name)
+	// standard library first
+	var std, other []string
+	for _, spec := range g.file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if !names[g.imports[path]] {
+			continue
+		}
+		imp := spec.Path.Value
+		if spec.Name != nil {
+			imp = spec.Name.Name + " " + imp
+		}
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			other = append(other, imp)
+		} else {
+			std = append(std, imp)
+		}
+	}
+	fmt.Fprintf(&b, "import (\n%s\n\n%s\n)\n\n", strings.Join(std, "\n"), strings.Join(other, "\n"))
+	b.WriteString(code)
+	return format.Source(b.Bytes())
+}
@@ -0,0 +1,67 @@
+// Command linqgen translates query comprehensions in marked comments into nested linq.Bind calls,
+// see doc.txt for the translation. For foo.go
+//
+//	//go:generate go run github.com/goghcrow/go-linq-object/cmd/linqgen
+//
+//	//linq:query crossJoin(xs []string, ys []int)
+//	//	from x in linq.FromSlice(xs)
+//	//	from y in linq.FromSlice(ys)
+//	//	where x != "b"
+//	//	select x + strconv.Itoa(y)
+//
+// generates into foo_linq.go
+//
+//	func crossJoin(xs []string, ys []int) linq.Seq[string] {
+//		return linq.Bind(linq.FromSlice(xs), func(x string) linq.Seq[string] {
+//			return linq.Bind(linq.FromSlice(ys), func(y int) linq.Seq[string] {
+//				if !(x != "b") {
+//					return linq.From[string]()
+//				}
+//				return linq.Return(x + strconv.Itoa(y))
+//			})
+//		})
+//	}
+//
+// The clauses are from, where, let, join [into], orderby [ascending | descending],
+// select [into] and group by [into], the types of the range variables and the result
+// are inferred with go/types, the queries must be at the top level of the file.
+package main
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("linqgen: ")
+
+	files := os.Args[1:]
+	if len(files) == 0 {
+		if f := os.Getenv("GOFILE"); f != "" {
+			files = []string{f}
+		} else {
+			log.Fatal("usage: linqgen [file.go ...]")
+		}
+	}
+	for _, f := range files {
+		src, err := generate(f)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if src == nil {
+			continue
+		}
+		if err := os.WriteFile(outputName(f), src, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func outputName(f string) string {
+	if base, ok := strings.CutSuffix(f, "_test.go"); ok {
+		return base + "_linq_test.go"
+	}
+	return strings.TrimSuffix(f, ".go") + "_linq.go"
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// TestGolden generates testdata/*/query.go, compares with query_linq.go.golden,
+// type-checks the package together with the generated file, and runs its query_test.go
+func TestGolden(t *testing.T) {
+	dirs, _ := filepath.Glob("testdata/*")
+	for _, dir := range dirs {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			src := filepath.Join(dir, "query.go")
+			golden := filepath.Join(dir, "query_linq.go.golden")
+			out, err := generate(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *update {
+				if err := os.WriteFile(golden, out, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != string(want) {
+				t.Errorf("%s differs from the golden file, got\n%s", src, out)
+			}
+
+			fset := token.NewFileSet()
+			var files []*ast.File
+			for name, code := range map[string]any{src: nil, "query_linq.go": out} {
+				f, err := parser.ParseFile(fset, filepath.Join(dir, filepath.Base(name)), code, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				files = append(files, f)
+			}
+			conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+			if _, err := conf.Check(dir, fset, files, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			if !testing.Short() {
+				runGenerated(t, dir, out)
+			}
+		})
+	}
+}
+
+// runGenerated runs go test on dir, with the generated file laid over by -overlay
+func runGenerated(t *testing.T, dir string, out []byte) {
+	tmp := t.TempDir()
+	gen := filepath.Join(tmp, "query_linq.go")
+	if err := os.WriteFile(gen, out, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	abs, err := filepath.Abs(filepath.Join(dir, "query_linq.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlay, _ := json.Marshal(map[string]any{"Replace": map[string]string{abs: gen}})
+	overlayFile := filepath.Join(tmp, "overlay.json")
+	if err := os.WriteFile(overlayFile, overlay, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("go", "test", "-overlay", overlayFile, "./"+filepath.ToSlash(dir))
+	if msg, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("%v\n%s", err, msg)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for query, want := range map[string]string{
+		"select x":                                                "query must start with from",
+		"from x in xs":                                            "query must end with select or group",
+		"from x xs select x":                                      "expect in",
+		"from x in xs where select x":                             "expect expression",
+		"from x in xs let y x select y":                           "expect =",
+		"from x in xs join y in ys on x select y":                 "expect equals",
+		"from x in xs group x select x":                           "expect by",
+		"from x in xs select x from y in xs select y":             "select must end the query",
+		"from x in xs from x in xs select x":                      "range variable x redeclared",
+		"from x in 42 select x":                                   "not a linq.Seq",
+		"from x in xs select y":                                   "undefined: y",
+		"from x in xs join y in xs on x equals y into g select y": "undefined: y",
+	} {
+		t.Run(query, func(t *testing.T) {
+			t.Parallel()
+			err := generateQuery(t, query)
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("got %v, want %s", err, want)
+			}
+		})
+	}
+}
+
+func generateQuery(t *testing.T, query string) error {
+	dir := t.TempDir()
+	src := `package q
+
+import "github.com/goghcrow/go-linq-object"
+
+//linq:query q(xs linq.Seq[int])
+//	` + query + "\n"
+	f := filepath.Join(dir, "q.go")
+	if err := os.WriteFile(f, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := generate(f)
+	return err
+}
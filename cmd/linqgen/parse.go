@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+// ↓↓↓↓↓↓ Query Comprehension ↓↓↓↓↓↓
+//
+//	query   = from { from | where | let | join | orderby } ( select | group ) [ into ident query-body ]
+//	from    = "from" ident "in" expr
+//	where   = "where" expr
+//	let     = "let" ident "=" expr
+//	join    = "join" ident "in" expr "on" expr "equals" expr [ "into" ident ]
+//	orderby = "orderby" expr [ "ascending" | "descending" ] { "," expr [ "ascending" | "descending" ] }
+//	select  = "select" expr
+//	group   = "group" expr "by" expr
+//
+// the expressions are Go expressions, cut at the next keyword outside of brackets
+
+type clause struct {
+	kind  string   // from, where, let, join, orderby, select, group
+	name  string   // the range variable of from, let, join
+	exprs []string // from: src; where: cond; let: value; join: src, outer key, inner key; orderby: keys; select: value; group: value, key
+	desc  []bool   // orderby
+	into  string   // join, select, group
+}
+
+var clauseKeywords = map[string]bool{
+	"from": true, "where": true, "let": true, "join": true, "orderby": true, "select": true, "group": true,
+}
+
+type tok struct {
+	off, end int
+	tok      token.Token
+	lit      string
+}
+
+type queryParser struct {
+	src  string
+	toks []tok
+	i    int
+}
+
+func parseQuery(src string) (cs []clause, err error) {
+	p := &queryParser{src: src}
+	if err := p.scan(); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(parseError)
+			if !ok {
+				panic(r)
+			}
+			cs, err = nil, e
+		}
+	}()
+
+	if !p.is("from") {
+		p.fail("query must start with from")
+	}
+	for !p.eof() {
+		cs = append(cs, p.clause())
+	}
+	return
+}
+
+type parseError string
+
+func (e parseError) Error() string { return string(e) }
+
+func (p *queryParser) fail(format string, args ...any) {
+	at := "end of query"
+	if !p.eof() {
+		at = fmt.Sprintf("%q", p.toks[p.i].text(p.src))
+	}
+	panic(parseError(fmt.Sprintf(format, args...) + ", at " + at))
+}
+
+func (p *queryParser) scan() error {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", -1, len(p.src))
+	var errs scanner.ErrorList
+	var s scanner.Scanner
+	s.Init(file, []byte(p.src), func(pos token.Position, msg string) { errs.Add(pos, msg) }, 0)
+	for {
+		pos, t, lit := s.Scan()
+		if t == token.EOF {
+			break
+		}
+		if t == token.SEMICOLON && lit == "\n" {
+			continue
+		}
+		off := file.Offset(pos)
+		end := off + len(lit)
+		if lit == "" {
+			end = off + len(t.String())
+		}
+		p.toks = append(p.toks, tok{off, end, t, lit})
+	}
+	return errs.Err()
+}
+
+func (t tok) text(src string) string { return src[t.off:t.end] }
+
+func (p *queryParser) eof() bool { return p.i >= len(p.toks) }
+
+// is reports whether the next token is the keyword w, select is a Go keyword and the rest are identifiers
+func (p *queryParser) is(w string) bool {
+	if p.eof() {
+		return false
+	}
+	t := p.toks[p.i]
+	return t.tok == token.IDENT && t.lit == w || t.tok == token.SELECT && w == "select"
+}
+
+func (p *queryParser) accept(w string) bool {
+	if p.is(w) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) expect(w string) {
+	if !p.accept(w) {
+		p.fail("expect %s", w)
+	}
+}
+
+func (p *queryParser) ident() string {
+	if p.eof() || p.toks[p.i].tok != token.IDENT || clauseKeywords[p.toks[p.i].lit] {
+		p.fail("expect identifier")
+	}
+	p.i++
+	return p.toks[p.i-1].lit
+}
+
+// expr reads up to a clause keyword or one of stops outside of brackets
+func (p *queryParser) expr(stops ...string) string {
+	start, depth := p.i, 0
+	for ; !p.eof(); p.i++ {
+		switch p.toks[p.i].tok {
+		case token.LPAREN, token.LBRACK, token.LBRACE:
+			depth++
+			continue
+		case token.RPAREN, token.RBRACK, token.RBRACE:
+			depth--
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+		if p.atClause() {
+			break
+		}
+		stop := false
+		for _, w := range stops {
+			if w == "," && p.toks[p.i].tok == token.COMMA || p.is(w) {
+				stop = true
+			}
+		}
+		if stop {
+			break
+		}
+	}
+	if p.i == start {
+		p.fail("expect expression")
+	}
+	return p.src[p.toks[start].off:p.toks[p.i-1].end]
+}
+
+func (p *queryParser) atClause() bool {
+	for w := range clauseKeywords {
+		if p.is(w) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *queryParser) clause() (c clause) {
+	switch {
+	case p.accept("from"):
+		c.kind, c.name = "from", p.ident()
+		p.expect("in")
+		c.exprs = []string{p.expr()}
+	case p.accept("where"):
+		c.kind, c.exprs = "where", []string{p.expr()}
+	case p.accept("let"):
+		c.kind, c.name = "let", p.ident()
+		if p.eof() || p.toks[p.i].tok != token.ASSIGN {
+			p.fail("expect =")
+		}
+		p.i++
+		c.exprs = []string{p.expr()}
+	case p.accept("join"):
+		c.kind, c.name = "join", p.ident()
+		p.expect("in")
+		src := p.expr("on")
+		p.expect("on")
+		outer := p.expr("equals")
+		p.expect("equals")
+		c.exprs = []string{src, outer, p.expr("into")}
+		if p.accept("into") {
+			c.into = p.ident()
+		}
+	case p.accept("orderby"):
+		c.kind = "orderby"
+		for {
+			c.exprs = append(c.exprs, p.expr(",", "ascending", "descending"))
+			p.accept("ascending")
+			c.desc = append(c.desc, p.accept("descending"))
+			if p.eof() || p.toks[p.i].tok != token.COMMA {
+				break
+			}
+			p.i++
+		}
+	case p.accept("select"):
+		c.kind, c.exprs = "select", []string{p.expr("into")}
+		if p.accept("into") {
+			c.into = p.ident()
+		}
+	case p.accept("group"):
+		c.kind = "group"
+		val := p.expr("by")
+		p.expect("by")
+		c.exprs = []string{val, p.expr("into")}
+		if p.accept("into") {
+			c.into = p.ident()
+		}
+	default:
+		p.fail("expect one of %s", strings.Join([]string{"from", "where", "let", "join", "orderby", "select", "group"}, ", "))
+	}
+	return
+}
@@ -0,0 +1,20 @@
+package crossjoin
+
+import (
+	"strconv"
+
+	"github.com/goghcrow/go-linq-object"
+)
+
+// an import used by the queries only must be kept in the file
+var _ = strconv.Itoa
+
+//linq:query crossJoin(xs []string, ys []int)
+//	from x in linq.FromSlice(xs)
+//	from y in linq.FromSlice(ys)
+//	where x != "b"
+//	select x + strconv.Itoa(y)
+
+func CrossJoin(xs []string, ys []int) []string {
+	return linq.ToSlice(crossJoin(xs, ys))
+}
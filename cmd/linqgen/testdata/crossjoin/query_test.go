@@ -0,0 +1,13 @@
+package crossjoin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCrossJoin(t *testing.T) {
+	got := CrossJoin([]string{"a", "b", "c"}, []int{1, 2})
+	if want := []string{"a1", "a2", "c1", "c2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+package pets
+
+import (
+	"strings"
+
+	"github.com/goghcrow/go-linq-object"
+)
+
+type (
+	Person struct {
+		Name string
+	}
+	Pet struct {
+		Name  string
+		Owner string
+		Age   int
+	}
+)
+
+// an import used by the queries only must be kept in the file
+var _ = strings.ToUpper
+
+//linq:query upperNames(pets []Pet)
+//	from p in linq.FromSlice(pets)
+//	let name = strings.ToUpper(p.Name)
+//	where p.Age > 1
+//	orderby p.Age descending, name
+//	select name
+
+//linq:query petOwners(people []Person, pets []Pet)
+//	from person in linq.FromSlice(people)
+//	join pet in linq.FromSlice(pets) on person.Name equals pet.Owner
+//	select person.Name + ":" + pet.Name
+
+//linq:query petCounts(people []Person, pets []Pet)
+//	from person in linq.FromSlice(people)
+//	join pet in linq.FromSlice(pets) on person.Name equals pet.Owner into owned
+//	let n = len(linq.ToSlice(owned))
+//	orderby n descending
+//	select linq.Cons[string, int]{person.Name, n}
+
+//linq:query byAge(pets []Pet)
+//	from p in linq.FromSlice(pets)
+//	group p by p.Age
+
+//linq:query namesByAge(pets []Pet)
+//	from p in linq.FromSlice(pets)
+//	group p.Name by p.Age into g
+//	orderby g.Key
+//	select strings.Join(linq.ToSlice(g.Seq), ",")
+
+//linq:query sorted(xs linq.Seq[int])
+//	from x in xs
+//	orderby x
+//	select x
+
+//linq:query squares(n int)
+//	from x in linq.Range(0, n)
+//	select x * x into sq
+//	where sq%2 == 0
+//	select sq
+
+func UpperNames(pets []Pet) []string {
+	return linq.ToSlice(upperNames(pets))
+}
@@ -0,0 +1,39 @@
+package pets
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goghcrow/go-linq-object"
+)
+
+func assertEqual(t *testing.T, x, y any) {
+	if !reflect.DeepEqual(x, y) {
+		t.Errorf("got %v, want %v", x, y)
+	}
+}
+
+var (
+	people = []Person{{"Magnus"}, {"Terry"}, {"Charlotte"}, {"Arlene"}}
+	pets   = []Pet{
+		{"Barley", "Terry", 8},
+		{"Daisy", "Magnus", 4},
+		{"Whiskers", "Charlotte", 1},
+		{"Boots", "Terry", 4},
+	}
+)
+
+func TestQueries(t *testing.T) {
+	assertEqual(t, UpperNames(pets), []string{"BARLEY", "BOOTS", "DAISY"})
+	assertEqual(t, linq.ToSlice(petOwners(people, pets)),
+		[]string{"Magnus:Daisy", "Terry:Barley", "Terry:Boots", "Charlotte:Whiskers"})
+	assertEqual(t, linq.ToSlice(petCounts(people, pets)), []linq.Cons[string, int]{
+		{Car: "Terry", Cdr: 2}, {Car: "Magnus", Cdr: 1}, {Car: "Charlotte", Cdr: 1}, {Car: "Arlene", Cdr: 0},
+	})
+	assertEqual(t, linq.ToSlice(linq.Select(byAge(pets), func(g linq.Grouping[int, Pet]) linq.Cons[int, int] {
+		return linq.Cons[int, int]{Car: g.Key, Cdr: len(linq.ToSlice(g.Seq))}
+	})), []linq.Cons[int, int]{{Car: 8, Cdr: 1}, {Car: 4, Cdr: 2}, {Car: 1, Cdr: 1}})
+	assertEqual(t, linq.ToSlice(namesByAge(pets)), []string{"Whiskers", "Daisy,Boots", "Barley"})
+	assertEqual(t, linq.ToSlice(sorted(linq.From(3, 1, 2))), []int{1, 2, 3})
+	assertEqual(t, linq.ToSlice(squares(5)), []int{0, 4, 16})
+}
@@ -0,0 +1,25 @@
+package linq
+
+type (
+	Signed interface {
+		~int | ~int8 | ~int16 | ~int32 | ~int64
+	}
+	Unsigned interface {
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+	}
+	Integer interface{ Signed | Unsigned }
+	Float   interface{ ~float32 | ~float64 }
+	Number  interface{ Integer | Float }
+	Ordered interface{ Number | ~string }
+)
+
+func compare[K Ordered](x, y K) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -14,3 +14,64 @@ func Const[Any, R any](x R) func(Any) R {
 func Id[A any](a A) A {
 	return a
 }
+
+// Lookup is a read-only multi-map that remembers first-seen key order
+type Lookup[K comparable, V any] struct {
+	keys []K
+	vals map[K][]V
+}
+
+func toLookup[A any, K comparable, V any](xs Seq[A], key Selector[A, K], val Selector[A, V]) (*Lookup[K, V], error) {
+	l := &Lookup[K, V]{vals: map[K][]V{}}
+	err := IterateE(xs, func(x A) error {
+		l.add(key(x), val(x))
+		return nil
+	})
+	return l, err
+}
+
+func (l *Lookup[K, V]) add(k K, v V) {
+	vs, ok := l.vals[k]
+	if !ok {
+		l.keys = append(l.keys, k)
+	}
+	l.vals[k] = append(vs, v)
+}
+
+func (l *Lookup[K, V]) get(k K) []V {
+	return l.vals[k]
+}
+
+// Len returns the number of keys
+func (l *Lookup[K, V]) Len() int {
+	return len(l.keys)
+}
+
+func (l *Lookup[K, V]) Contains(k K) bool {
+	_, ok := l.vals[k]
+	return ok
+}
+
+// Get returns an empty sequence for missing key
+func (l *Lookup[K, V]) Get(k K) Seq[V] {
+	return FromSlice(l.get(k))
+}
+
+func (l *Lookup[K, V]) Keys() Seq[K] {
+	return FromSlice(l.keys)
+}
+
+func (l *Lookup[K, V]) Groups() Seq[Grouping[K, V]] {
+	return Select(l.Keys(), func(k K) Grouping[K, V] {
+		return Grouping[K, V]{Key: k, Seq: l.Get(k)}
+	})
+}
+
+func MakeCons[T1, T2 any](car T1, cdr T2) Cons[T1, T2] {
+	return Cons[T1, T2]{car, cdr}
+}
+
+// Clone copies a slice, e.g. Select(Chunk(xs, n), Clone[T])
+func Clone[T any](xs []T) []T {
+	return append([]T(nil), xs...)
+}
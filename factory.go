@@ -1,9 +1,39 @@
 package linq
 
+import (
+	"io"
+	"time"
+)
+
 func SeqOf[T any](f FSeq[T]) Seq[T] {
 	return f
 }
 
+// SeqOfE stops at the first error of f, which is reported by Err
+func SeqOfE[T any](f func() (T, bool, error)) Seq[T] {
+	var err error
+	return &wrapSeq[T]{
+		Seq: SeqOf[T](func() (x T, ok bool) {
+			if err != nil {
+				return
+			}
+			if x, ok, err = f(); err != nil {
+				var zero T
+				return zero, false
+			}
+			return
+		}),
+		err: func() error { return err },
+	}
+}
+
+// Fail is an empty sequence with err
+func Fail[T any](err error) Seq[T] {
+	return SeqOfE[T](func() (x T, ok bool, _ error) {
+		return x, false, err
+	})
+}
+
 func From[T any](xs ...T) Seq[T] {
 	// return &SliceSeq[T]{xs: xs}
 	var i int
@@ -16,9 +46,16 @@ func From[T any](xs ...T) Seq[T] {
 	})
 }
 
+// EnumerableOf calls f for a fresh enumerator on every iteration
+func EnumerableOf[T any](f func() Seq[T]) Enumerable[T] {
+	return &FEnumerable[T]{f: f}
+}
+
 func FromSlice[T any](xs []T) Seq[T] {
 	// return From(xs...)
-	return &SliceSeq[T]{xs: xs}
+	e := indexedEnumerableOf(len(xs), func(i int) T { return xs[i] })
+	e.xs = xs
+	return e
 }
 
 func FromMap[K comparable, V any](xs map[K]V) Seq[Cons[K, V]] {
@@ -32,12 +69,152 @@ func FromMap[K comparable, V any](xs map[K]V) Seq[Cons[K, V]] {
 }
 
 func Range(start, end int) Seq[int] {
-	i := start
-	return SeqOf[int](func() (x int, ok bool) {
-		ok = i < end
-		if ok {
-			x, i = i, i+1
+	return indexedEnumerableOf(end-start, func(i int) int { return start + i })
+}
+
+// RangeStep is Range with step, negative step counts down to end exclusive
+func RangeStep(start, end, step int) Seq[int] {
+	return NumberRange(start, end, step)
+}
+
+// NumberRange computes start + i*step, so that float ranges don't drift,
+// and stops on integer wraparound
+func NumberRange[N Number](start, end, step N) Seq[N] {
+	if step == 0 {
+		panic("linq: range step must not be zero")
+	}
+	return EnumerableOf(func() Seq[N] {
+		var i N
+		prev := start
+		return SeqOf[N](func() (x N, ok bool) {
+			x = start + i*step
+			if step > 0 {
+				ok = x < end && (i == 0 || x > prev)
+			} else {
+				ok = x > end && (i == 0 || x < prev)
+			}
+			if ok {
+				i, prev = i+1, x
+			}
+			return
+		})
+	})
+}
+
+func DateRange(start, end time.Time, step time.Duration) Seq[time.Time] {
+	if step == 0 {
+		panic("linq: range step must not be zero")
+	}
+	return EnumerableOf(func() Seq[time.Time] {
+		i := 0
+		return SeqOf[time.Time](func() (x time.Time, ok bool) {
+			x = start.Add(time.Duration(i) * step)
+			ok = step > 0 && x.Before(end) || step < 0 && x.After(end)
+			if ok {
+				i++
+			}
+			return
+		})
+	})
+}
+
+// Repeat x n times
+func Repeat[T any](x T, n int) Seq[T] {
+	return Take(Infinite(x), n)
+}
+
+func Infinite[T any](x T) Seq[T] {
+	return EnumerableOf(func() Seq[T] {
+		return Generate(func() T {
+			return x
+		})
+	})
+}
+
+// Generate an infinite sequence by calling f, which is not re-enumerable
+func Generate[T any](f func() T) Seq[T] {
+	return SeqOf[T](func() (T, bool) {
+		return f(), true
+	})
+}
+
+// Unfold aka anamorphism, f returns the next element and the next seed,
+// or false to stop
+func Unfold[S, T any](seed S, f func(S) (T, S, bool)) Seq[T] {
+	return EnumerableOf(func() Seq[T] {
+		s, end := seed, false
+		return SeqOf[T](func() (x T, ok bool) {
+			if end {
+				return
+			}
+			if x, s, ok = f(s); !ok {
+				end = true
+			}
+			return
+		})
+	})
+}
+
+// Cycle repeats xs forever, elements are buffered on the first pass
+func Cycle[T any](xs Seq[T]) Seq[T] {
+	return lift(xs, func(xs Seq[T]) Seq[T] {
+		var buf []T
+		i := 0
+		return SeqOf[T](func() (x T, ok bool) {
+			if xs != nil {
+				if x, ok = xs.Next(); ok {
+					buf = append(buf, x)
+					return
+				}
+				if Err(xs) != nil {
+					buf = nil
+				}
+				xs = nil
+			}
+			if len(buf) == 0 {
+				return
+			}
+			x, i = buf[i], (i+1)%len(buf)
+			return x, true
+		})
+	})
+}
+
+// Defer calls factory on the first Next of every iteration
+func Defer[T any](factory func() Seq[T]) Seq[T] {
+	return EnumerableOf(factory)
+}
+
+// Using acquires the resource on the first Next of every iteration,
+// and releases it at the end of the body or on Close
+func Using[R io.Closer, T any](acquire func() R, body func(R) Seq[T]) Seq[T] {
+	return Defer(func() Seq[T] {
+		r := acquire()
+		ys := onClose(body(r), r.Close)
+		return &wrapSeq[T]{
+			Seq: SeqOf[T](func() (x T, ok bool) {
+				if x, ok = ys.Next(); !ok {
+					_ = Close(ys)
+				}
+				return
+			}),
+			close: func() error { return Close(ys) },
+			err:   func() error { return Err(ys) },
 		}
-		return
 	})
 }
+
+// lazy calls f on the first Next
+func lazy[T any](f func() Seq[T]) Seq[T] {
+	var xs Seq[T]
+	return &wrapSeq[T]{
+		Seq: SeqOf[T](func() (T, bool) {
+			if xs == nil {
+				xs = f()
+			}
+			return xs.Next()
+		}),
+		close: func() error { return Close(xs) },
+		err:   func() error { return Err(xs) },
+	}
+}
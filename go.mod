@@ -1,3 +1,3 @@
 module github.com/goghcrow/go-linq-object
 
-go 1.19
+go 1.23
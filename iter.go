@@ -0,0 +1,102 @@
+package linq
+
+import "iter"
+
+// ↓↓↓↓↓↓ range-over-func interop ↓↓↓↓↓↓
+// All is taken by the quantifier, so Values / Enumerate follow slices.Values / slices.All
+
+// Values adapts to iter.Seq, which can be ranged over again if xs is re-enumerable, e.g. for x := range Values(xs) or slices.Collect(Values(xs))
+func Values[T any](xs Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		xs := enumerator(xs)
+		for {
+			x, ok := xs.Next()
+			if !ok {
+				return
+			}
+			if !yield(x) {
+				_ = Close(xs)
+				return
+			}
+		}
+	}
+}
+
+// Enumerate adapts to iter.Seq2 with index
+func Enumerate[T any](xs Seq[T]) iter.Seq2[Index, T] {
+	return func(yield func(Index, T) bool) {
+		xs := enumerator(xs)
+		for i := 0; ; i++ {
+			x, ok := xs.Next()
+			if !ok {
+				return
+			}
+			if !yield(i, x) {
+				_ = Close(xs)
+				return
+			}
+		}
+	}
+}
+
+// Pairs adapts to iter.Seq2, e.g. maps.Collect(Pairs(FromMap(m)))
+func Pairs[K, V any](xs Seq[Cons[K, V]]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		xs := enumerator(xs)
+		for {
+			x, ok := xs.Next()
+			if !ok {
+				return
+			}
+			if !yield(x.Car, x.Cdr) {
+				_ = Close(xs)
+				return
+			}
+		}
+	}
+}
+
+// FromIter pulls from seq by iter.Pull, which is stopped at the end of seq,
+// call Close to stop it if the sequence is abandoned before the end
+func FromIter[T any](seq iter.Seq[T]) Seq[T] {
+	return &pullSeq[T]{seq: seq}
+}
+
+func FromIter2[K, V any](seq iter.Seq2[K, V]) Seq[Cons[K, V]] {
+	return FromIter(func(yield func(Cons[K, V]) bool) {
+		for k, v := range seq {
+			if !yield(Cons[K, V]{k, v}) {
+				return
+			}
+		}
+	})
+}
+
+type pullSeq[T any] struct {
+	seq  iter.Seq[T]
+	next func() (T, bool)
+	stop func()
+}
+
+func (s *pullSeq[T]) Next() (x T, ok bool) {
+	if s.seq != nil {
+		s.next, s.stop = iter.Pull(s.seq)
+		s.seq = nil
+	}
+	if s.next == nil {
+		return
+	}
+	if x, ok = s.next(); !ok {
+		_ = s.Close()
+	}
+	return
+}
+
+func (s *pullSeq[T]) Close() error {
+	s.seq = nil
+	if s.stop != nil {
+		s.stop()
+		s.next, s.stop = nil, nil
+	}
+	return nil
+}
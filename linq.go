@@ -1,5 +1,13 @@
 package linq
 
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/goghcrow/go-linq-object/maybe"
+)
+
 type (
 	Index                           = int
 	Pred[T any]                     func(T) bool
@@ -13,34 +21,44 @@ func SelectMany[A, R any](xs Seq[A], f func(A) Seq[R]) Seq[R] {
 }
 
 func SelectManyWithIndex[A, R any](xs Seq[A], f func(A, Index) Seq[R]) Seq[R] {
-	idx := 0
-	return SelectMany(xs, func(a A) (r Seq[R]) {
-		r, idx = f(a, idx), idx+1
-		return
+	return lift(xs, func(xs Seq[A]) Seq[R] {
+		idx := 0
+		return SelectMany(xs, func(a A) (r Seq[R]) {
+			r, idx = f(a, idx), idx+1
+			return
+		})
 	})
 }
 
-// Select aka map
+// Select aka map, fused with the adjacent Where / Select
 func Select[A, R any](xs Seq[A], f Selector[A, R]) Seq[R] {
-	return SelectWithIndex(xs, func(x A, _ Index) R {
-		return f(x)
-	})
+	xs = unwrap(xs)
+	if s, ok := xs.(stage[A, A]); ok {
+		src, g := s.stage()
+		return fuse(src, func(x A) (r R, ok bool) {
+			if x, ok = g(x); ok {
+				r = f(x)
+			}
+			return
+		})
+	}
+	if s, ok := indexedOf(xs); ok {
+		return indexedEnumerableOf(s.Len(), func(i int) R { return f(s.At(i)) })
+	}
+	return fuse(xs, func(x A) (R, bool) { return f(x), true })
 }
 
 func SelectWithIndex[A, R any](xs Seq[A], f IdxSelector[A, R]) Seq[R] {
-	// // e.g.
-	// i := 0
-	// return SeqOf(func() (r R, ok bool) {
-	// 	x, ok := xs.Next()
-	// 	if !ok {
-	// 		return
-	// 	}
-	// 	r, ok, i = f(x, i), true, i+1
-	// 	return
-	// })
-
-	return SelectManyWithIndex(xs, func(x A, i Index) Seq[R] {
-		return Return(f(x, i))
+	return lift(xs, func(xs Seq[A]) Seq[R] {
+		i := 0
+		return SeqOf[R](func() (r R, ok bool) {
+			x, ok := xs.Next()
+			if !ok {
+				return
+			}
+			r, i = f(x, i), i+1
+			return r, true
+		})
 	})
 }
 
@@ -48,11 +66,20 @@ func First[A any](xs Seq[A]) (A, bool) {
 	return Take(xs, 1).Next()
 }
 
-func FirstWhile[A any](xs Seq[A], p Pred[A]) (A, bool) {
-	return TakeWhile(xs, p).Next()
+func FirstWhile[A any](xs Seq[A], p Pred[A]) (x A, ok bool) {
+	ys := TakeWhile(xs, p)
+	x, ok = ys.Next()
+	_ = Close(ys)
+	return
 }
 
 func Last[A any](xs Seq[A]) (last A, ok bool) {
+	if s, ok := indexedOf(xs); ok {
+		if n := s.Len(); n > 0 {
+			return s.At(n - 1), true
+		}
+		return last, false
+	}
 	return LastWhile(xs, Const[A](true))
 }
 
@@ -66,28 +93,131 @@ func LastWhile[A any](xs Seq[A], p Pred[A]) (last A, ok bool) {
 	return
 }
 
+// ElementAt returns the element at index i, counting from 0
+func ElementAt[A any](xs Seq[A], i Index) (A, bool) {
+	if i < 0 {
+		var zero A
+		return zero, false
+	}
+	if s, ok := indexedOf(xs); ok {
+		if i < s.Len() {
+			return s.At(i), true
+		}
+		var zero A
+		return zero, false
+	}
+	return First(Skip(xs, i))
+}
+
+func ElementAtOrDefault[A any](xs Seq[A], i Index, def A) A {
+	if x, ok := ElementAt(xs, i); ok {
+		return x
+	}
+	return def
+}
+
+func FirstOrDefault[A any](xs Seq[A], def A) A {
+	if x, ok := First(xs); ok {
+		return x
+	}
+	return def
+}
+
+func LastOrDefault[A any](xs Seq[A], def A) A {
+	if x, ok := Last(xs); ok {
+		return x
+	}
+	return def
+}
+
+var (
+	ErrNoElements       = errors.New("linq: sequence contains no elements")
+	ErrMultipleElements = errors.New("linq: sequence contains more than one element")
+)
+
+// Single returns the only element, or ErrNoElements / ErrMultipleElements
+func Single[A any](xs Seq[A]) (A, error) {
+	return SingleWhile(xs, Const[A](true))
+}
+
+func SingleWhile[A any](xs Seq[A], p Pred[A]) (x A, err error) {
+	ys := Where(xs, p)
+	defer Close(ys)
+	x, ok := ys.Next()
+	if !ok {
+		return x, ErrNoElements
+	}
+	if _, ok = ys.Next(); ok {
+		var zero A
+		return zero, ErrMultipleElements
+	}
+	return
+}
+
+// ↓↓↓↓↓↓ Maybe ↓↓↓↓↓↓
+
+func FirstMaybe[A any](xs Seq[A]) maybe.Maybe[A] { return maybe.Of(First(xs)) }
+
+func FirstWhileMaybe[A any](xs Seq[A], p Pred[A]) maybe.Maybe[A] {
+	return maybe.Of(FirstWhile(xs, p))
+}
+
+func LastMaybe[A any](xs Seq[A]) maybe.Maybe[A] { return maybe.Of(Last(xs)) }
+
+func LastWhileMaybe[A any](xs Seq[A], p Pred[A]) maybe.Maybe[A] {
+	return maybe.Of(LastWhile(xs, p))
+}
+
+func ElementAtMaybe[A any](xs Seq[A], i Index) maybe.Maybe[A] {
+	return maybe.Of(ElementAt(xs, i))
+}
+
+// SingleMaybe is Nothing for both empty and more than one element
+func SingleMaybe[A any](xs Seq[A]) maybe.Maybe[A] {
+	x, err := Single(xs)
+	return maybe.Of(x, err == nil)
+}
+
+// Where aka filter, fused with the adjacent Where / Select
 func Where[A any](xs Seq[A], p Pred[A]) Seq[A] {
-	return WhereWithIndex(xs, func(x A, _ Index) bool {
-		return p(x)
-	})
+	xs = unwrap(xs)
+	if s, ok := xs.(whereStage[A]); ok {
+		return s.where(p)
+	}
+	return fuse(xs, func(x A) (A, bool) { return x, p(x) })
 }
 
 func WhereWithIndex[A any](xs Seq[A], p IdxPred[A]) Seq[A] {
-	return SelectManyWithIndex(xs, func(x A, i Index) Seq[A] {
-		if p(x, i) {
-			return Return(x)
-		}
-		return nil
+	return lift(xs, func(xs Seq[A]) Seq[A] {
+		i := 0
+		return SeqOf[A](func() (x A, ok bool) {
+			for {
+				if x, ok = xs.Next(); !ok {
+					return
+				}
+				if i++; p(x, i-1) {
+					return x, true
+				}
+			}
+		})
 	})
 }
 
 func Take[A any](xs Seq[A], cnt int) Seq[A] {
-	return SeqOf[A](func() (x A, ok bool) {
-		if cnt <= 0 {
+	return lift(xs, func(xs Seq[A]) Seq[A] {
+		cnt := cnt
+		return SeqOf[A](func() (x A, ok bool) {
+			if cnt <= 0 {
+				_ = Close(xs)
+				return
+			}
+			cnt--
+			x, ok = xs.Next()
+			if cnt == 0 {
+				_ = Close(xs)
+			}
 			return
-		}
-		cnt--
-		return xs.Next()
+		})
 	})
 }
 
@@ -102,12 +232,57 @@ func TakeWhileWithIndex[A any](xs Seq[A], p IdxPred[A]) Seq[A] {
 }
 
 func Skip[A any](xs Seq[A], cnt int) Seq[A] {
-	return SelectMany(xs, func(x A) Seq[A] {
-		if cnt <= 0 {
-			return Return(x)
+	if s, ok := indexedOf(xs); ok {
+		cnt = min(max(cnt, 0), s.Len())
+		if e, ok := s.(*indexedEnumerable[A]); ok && e.xs != nil {
+			return FromSlice(e.xs[cnt:])
 		}
-		cnt--
-		return nil
+		return indexedEnumerableOf(s.Len()-cnt, func(i int) A { return s.At(cnt + i) })
+	}
+	return lift(xs, func(xs Seq[A]) Seq[A] {
+		cnt := cnt
+		return SeqOf[A](func() (x A, ok bool) {
+			for ; cnt > 0; cnt-- {
+				if _, ok = xs.Next(); !ok {
+					return
+				}
+			}
+			return xs.Next()
+		})
+	})
+}
+
+// Count enumerates xs unless it is Indexed, it ignores the error of xs, see CountE
+func Count[A any](xs Seq[A]) int {
+	if s, ok := indexedOf(xs); ok {
+		return s.Len()
+	}
+	return Fold(xs, 0, func(n int, _ A) int { return n + 1 })
+}
+
+// TryGetNonEnumeratedCount returns the count of a Sized xs, which costs nothing
+func TryGetNonEnumeratedCount[A any](xs Seq[A]) (int, bool) {
+	if s, ok := unwrap(xs).(Sized); ok {
+		return s.Len(), true
+	}
+	return 0, false
+}
+
+// Reverse buffers xs on the first Next unless it is Indexed
+func Reverse[A any](xs Seq[A]) Seq[A] {
+	if s, ok := indexedOf(xs); ok {
+		n := s.Len()
+		return indexedEnumerableOf(n, func(i int) A { return s.At(n - 1 - i) })
+	}
+	return lift(xs, func(xs Seq[A]) Seq[A] {
+		return lazy(func() Seq[A] {
+			ys, err := ToSliceE(xs)
+			if err != nil {
+				return Fail[A](err)
+			}
+			slices.Reverse(ys)
+			return &SliceSeq[A]{xs: ys}
+		})
 	})
 }
 
@@ -150,6 +325,7 @@ func Fold[A, R any](xs Seq[A], init R, f func(acc R, cur A) R) (acc R) {
 
 // Reduce Aggregate
 func Reduce[A any](xs Seq[A], f func(acc A, cur A) A) (r A, ok bool) {
+	xs = enumerator(xs)
 	r, ok = xs.Next()
 	if !ok {
 		return
@@ -163,6 +339,7 @@ func Reduce[A any](xs Seq[A], f func(acc A, cur A) A) (r A, ok bool) {
 
 func All[A any](xs Seq[A], p Pred[A]) (r bool) {
 	r = true
+	xs = enumerator(xs)
 	for {
 		x, ok := xs.Next()
 		if !ok {
@@ -170,6 +347,7 @@ func All[A any](xs Seq[A], p Pred[A]) (r bool) {
 		}
 		r = r && p(x)
 		if !r {
+			_ = Close(xs)
 			break
 		}
 	}
@@ -177,11 +355,16 @@ func All[A any](xs Seq[A], p Pred[A]) (r bool) {
 }
 
 func AnyElem[A any](xs Seq[A]) bool {
+	xs = enumerator(xs)
 	_, ok := xs.Next()
+	if ok {
+		_ = Close(xs)
+	}
 	return ok
 }
 
 func Any[A any](xs Seq[A], p Pred[A]) (r bool) {
+	xs = enumerator(xs)
 	for {
 		x, ok := xs.Next()
 		if !ok {
@@ -189,6 +372,7 @@ func Any[A any](xs Seq[A], p Pred[A]) (r bool) {
 		}
 		r = r || p(x)
 		if r {
+			_ = Close(xs)
 			break
 		}
 	}
@@ -196,20 +380,52 @@ func Any[A any](xs Seq[A], p Pred[A]) (r bool) {
 }
 
 func Append[A any](xs Seq[A], a A) Seq[A] {
-	end := false
-	return SeqOf(func() (x A, ok bool) {
-		if end {
+	return lift(xs, func(xs Seq[A]) Seq[A] {
+		end := false
+		return SeqOf(func() (x A, ok bool) {
+			if end {
+				return
+			}
+			x, ok = xs.Next()
+			if !ok {
+				end = true
+				if Err(xs) != nil {
+					return
+				}
+				return a, true
+			}
 			return
-		}
-		x, ok = xs.Next()
-		if !ok {
-			end = true
-			return a, true
-		}
-		return
+		})
 	})
 }
 
+func Prepend[A any](xs Seq[A], a A) Seq[A] {
+	return lift(xs, func(xs Seq[A]) Seq[A] {
+		return Concat(Return(a), xs)
+	})
+}
+
+func Concat[A any](xs, ys Seq[A]) Seq[A] {
+	return Chain(xs, ys)
+}
+
+// Chain aka flatten, re-enumerable only if all of seqs are
+func Chain[A any](seqs ...Seq[A]) Seq[A] {
+	for _, xs := range seqs {
+		if _, ok := unwrap(xs).(Enumerable[A]); xs != nil && !ok {
+			// Close reaches the inputs not started yet as well
+			return onClose(Bind(From(seqs...), Id[Seq[A]]), func() error {
+				errs := make([]error, len(seqs))
+				for i, xs := range seqs {
+					errs[i] = Close(xs)
+				}
+				return errors.Join(errs...)
+			})
+		}
+	}
+	return Bind(FromSlice(seqs), Id[Seq[A]])
+}
+
 func Iterate[T any](xs Seq[T], f func(T)) {
 	IterateWithIndex(xs, func(x T, _ Index) {
 		f(x)
@@ -217,6 +433,7 @@ func Iterate[T any](xs Seq[T], f func(T)) {
 }
 
 func IterateWithIndex[T any](xs Seq[T], f func(T, Index)) {
+	xs = enumerator(xs)
 	i := 0
 	for {
 		x, ok := xs.Next()
@@ -229,6 +446,19 @@ func IterateWithIndex[T any](xs Seq[T], f func(T, Index)) {
 }
 
 func ToSlice[T any](xs Seq[T]) (ys []T) {
+	if s, ok := indexedOf(xs); ok {
+		if n := s.Len(); n > 0 {
+			ys = make([]T, n)
+			for i := range ys {
+				ys[i] = s.At(i)
+			}
+		}
+		return
+	}
+	if n, ok := TryGetNonEnumeratedCount(xs); ok && n > 0 {
+		ys = make([]T, 0, n)
+	}
+	xs = enumerator(xs)
 	for {
 		x, ok := xs.Next()
 		if !ok {
@@ -238,3 +468,724 @@ func ToSlice[T any](xs Seq[T]) (ys []T) {
 	}
 	return
 }
+
+// OrderBy sorts stably in ascending order of key
+func OrderBy[A any, K Ordered](xs Seq[A], key Selector[A, K]) OrderedSeq[A] {
+	return newOrderedSeq(xs, []keyComparer[A]{orderKey(key, false)})
+}
+
+func OrderByDescending[A any, K Ordered](xs Seq[A], key Selector[A, K]) OrderedSeq[A] {
+	return newOrderedSeq(xs, []keyComparer[A]{orderKey(key, true)})
+}
+
+// ThenBy performs a subsequent ordering in ascending order of key
+func ThenBy[A any, K Ordered](xs OrderedSeq[A], key Selector[A, K]) OrderedSeq[A] {
+	return xs.thenBy(orderKey(key, false))
+}
+
+func ThenByDescending[A any, K Ordered](xs OrderedSeq[A], key Selector[A, K]) OrderedSeq[A] {
+	return xs.thenBy(orderKey(key, true))
+}
+
+// OrderByFunc sorts stably by cmp, which returns a negative number when a < b, cf. slices.SortStableFunc
+func OrderByFunc[A any](xs Seq[A], cmp func(a, b A) int) OrderedSeq[A] {
+	return newOrderedSeq(xs, []keyComparer[A]{orderFunc(cmp, false)})
+}
+
+func OrderByDescendingFunc[A any](xs Seq[A], cmp func(a, b A) int) OrderedSeq[A] {
+	return newOrderedSeq(xs, []keyComparer[A]{orderFunc(cmp, true)})
+}
+
+func ThenByFunc[A any](xs OrderedSeq[A], cmp func(a, b A) int) OrderedSeq[A] {
+	return xs.thenBy(orderFunc(cmp, false))
+}
+
+func ThenByDescendingFunc[A any](xs OrderedSeq[A], cmp func(a, b A) int) OrderedSeq[A] {
+	return xs.thenBy(orderFunc(cmp, true))
+}
+
+func orderFunc[A any](cmp func(a, b A) int, desc bool) keyComparer[A] {
+	return func(xs []A) func(i, j int) int {
+		if desc {
+			return func(i, j int) int { return cmp(xs[j], xs[i]) }
+		}
+		return func(i, j int) int { return cmp(xs[i], xs[j]) }
+	}
+}
+
+func orderKey[A any, K Ordered](key Selector[A, K], desc bool) keyComparer[A] {
+	return func(xs []A) func(i, j int) int {
+		ks := make([]K, len(xs))
+		for i, x := range xs {
+			ks[i] = key(x)
+		}
+		if desc {
+			return func(i, j int) int { return compare(ks[j], ks[i]) }
+		}
+		return func(i, j int) int { return compare(ks[i], ks[j]) }
+	}
+}
+
+// GroupBy groups in first-seen key order
+func GroupBy[A any, K comparable](xs Seq[A], key Selector[A, K]) Seq[Grouping[K, A]] {
+	return GroupByWithElement(xs, key, Id[A])
+}
+
+func GroupByWithElement[A any, K comparable, E any](
+	xs Seq[A],
+	key Selector[A, K],
+	elem Selector[A, E],
+) Seq[Grouping[K, E]] {
+	return lift(xs, func(xs Seq[A]) Seq[Grouping[K, E]] {
+		return lazy(func() Seq[Grouping[K, E]] {
+			l, err := toLookup(xs, key, elem)
+			if err != nil {
+				return Fail[Grouping[K, E]](err)
+			}
+			return l.Groups()
+		})
+	})
+}
+
+func GroupByWithResult[A any, K comparable, R any](
+	xs Seq[A],
+	key Selector[A, K],
+	result func(K, Seq[A]) R,
+) Seq[R] {
+	return Select(GroupBy(xs, key), func(g Grouping[K, A]) R {
+		return result(g.Key, g.Seq)
+	})
+}
+
+// Join aka inner join, the inner sequence is hashed on the first Next
+func Join[O, I any, K comparable, R any](
+	outer Seq[O],
+	inner Seq[I],
+	outerKey Selector[O, K],
+	innerKey Selector[I, K],
+	result func(O, I) R,
+) Seq[R] {
+	return lift2(outer, inner, func(outer Seq[O], inner Seq[I]) Seq[R] {
+		return lazy(func() Seq[R] {
+			l, err := toLookup(inner, innerKey, Id[I])
+			if err != nil {
+				return Fail[R](err)
+			}
+			return SelectMany(outer, func(o O) Seq[R] {
+				return Select(FromSlice(l.get(outerKey(o))), func(i I) R {
+					return result(o, i)
+				})
+			})
+		})
+	})
+}
+
+func GroupJoin[O, I any, K comparable, R any](
+	outer Seq[O],
+	inner Seq[I],
+	outerKey Selector[O, K],
+	innerKey Selector[I, K],
+	result func(O, Seq[I]) R,
+) Seq[R] {
+	return lift2(outer, inner, func(outer Seq[O], inner Seq[I]) Seq[R] {
+		return lazy(func() Seq[R] {
+			l, err := toLookup(inner, innerKey, Id[I])
+			if err != nil {
+				return Fail[R](err)
+			}
+			return Select(outer, func(o O) R {
+				return result(o, FromSlice(l.get(outerKey(o))))
+			})
+		})
+	})
+}
+
+// LeftJoin aka left outer join, unmatched outer comes with Nothing
+func LeftJoin[O, I any, K comparable, R any](
+	outer Seq[O],
+	inner Seq[I],
+	outerKey Selector[O, K],
+	innerKey Selector[I, K],
+	result func(O, maybe.Maybe[I]) R,
+) Seq[R] {
+	return lift2(outer, inner, func(outer Seq[O], inner Seq[I]) Seq[R] {
+		return lazy(func() Seq[R] {
+			l, err := toLookup(inner, innerKey, Id[I])
+			if err != nil {
+				return Fail[R](err)
+			}
+			return SelectMany(outer, func(o O) Seq[R] {
+				is := l.get(outerKey(o))
+				if len(is) == 0 {
+					return Return(result(o, maybe.Nothing[I]()))
+				}
+				return Select(FromSlice(is), func(i I) R {
+					return result(o, maybe.Just(i))
+				})
+			})
+		})
+	})
+}
+
+// RightJoin aka right outer join, streams inner and hashes outer
+func RightJoin[O, I any, K comparable, R any](
+	outer Seq[O],
+	inner Seq[I],
+	outerKey Selector[O, K],
+	innerKey Selector[I, K],
+	result func(maybe.Maybe[O], I) R,
+) Seq[R] {
+	return LeftJoin(inner, outer, innerKey, outerKey, func(i I, o maybe.Maybe[O]) R {
+		return result(o, i)
+	})
+}
+
+// FullOuterJoin yields matched and unmatched outer in outer order,
+// then unmatched inner in inner order
+func FullOuterJoin[O, I any, K comparable, R any](
+	outer Seq[O],
+	inner Seq[I],
+	outerKey Selector[O, K],
+	innerKey Selector[I, K],
+	result func(maybe.Maybe[O], maybe.Maybe[I]) R,
+) Seq[R] {
+	return lift2(outer, inner, func(outer Seq[O], inner Seq[I]) Seq[R] {
+		return lazy(func() Seq[R] {
+			is, err := ToSliceE(inner)
+			if err != nil {
+				return Fail[R](err)
+			}
+			matched := map[K]bool{}
+
+			left := LeftJoin(outer, FromSlice(is), outerKey, innerKey, func(o O, i maybe.Maybe[I]) R {
+				if i.Just {
+					matched[outerKey(o)] = true
+				}
+				return result(maybe.Just(o), i)
+			})
+			right := lazy(func() Seq[R] {
+				return Select(
+					Where(FromSlice(is), func(i I) bool {
+						return !matched[innerKey(i)]
+					}),
+					func(i I) R {
+						return result(maybe.Nothing[O](), maybe.Just(i))
+					},
+				)
+			})
+			return Concat(left, right)
+		})
+	})
+}
+
+// Distinct keeps the first occurrence of each element
+func Distinct[A comparable](xs Seq[A]) Seq[A] {
+	return DistinctBy(xs, Id[A])
+}
+
+func DistinctBy[A any, K comparable](xs Seq[A], key Selector[A, K]) Seq[A] {
+	return lift(xs, func(xs Seq[A]) Seq[A] {
+		seen := map[K]bool{}
+		return Where(xs, func(x A) bool {
+			k := key(x)
+			if seen[k] {
+				return false
+			}
+			seen[k] = true
+			return true
+		})
+	})
+}
+
+func Union[A comparable](xs, ys Seq[A]) Seq[A] {
+	return UnionBy(xs, ys, Id[A])
+}
+
+func UnionBy[A any, K comparable](xs, ys Seq[A], key Selector[A, K]) Seq[A] {
+	return DistinctBy(Concat(xs, ys), key)
+}
+
+// Intersect streams xs, ys is materialized on the first Next
+func Intersect[A comparable](xs, ys Seq[A]) Seq[A] {
+	return IntersectBy(xs, ys, Id[A])
+}
+
+func IntersectBy[A any, K comparable](xs Seq[A], keys Seq[K], key Selector[A, K]) Seq[A] {
+	return lift2(xs, keys, func(xs Seq[A], keys Seq[K]) Seq[A] {
+		return lazy(func() Seq[A] {
+			set, err := toSet(keys)
+			if err != nil {
+				return Fail[A](err)
+			}
+			return Where(xs, func(x A) bool {
+				k := key(x)
+				if !set[k] {
+					return false
+				}
+				delete(set, k)
+				return true
+			})
+		})
+	})
+}
+
+// Except streams xs, ys is materialized on the first Next
+func Except[A comparable](xs, ys Seq[A]) Seq[A] {
+	return ExceptBy(xs, ys, Id[A])
+}
+
+func ExceptBy[A any, K comparable](xs Seq[A], keys Seq[K], key Selector[A, K]) Seq[A] {
+	return lift2(xs, keys, func(xs Seq[A], keys Seq[K]) Seq[A] {
+		return lazy(func() Seq[A] {
+			set, err := toSet(keys)
+			if err != nil {
+				return Fail[A](err)
+			}
+			return Where(xs, func(x A) bool {
+				k := key(x)
+				if set[k] {
+					return false
+				}
+				set[k] = true
+				return true
+			})
+		})
+	})
+}
+
+// Zip stops at the end of the shorter one, pass MakeCons to get pairs
+func Zip[A, B, R any](xs Seq[A], ys Seq[B], f func(A, B) R) Seq[R] {
+	return lift2(xs, ys, func(xs Seq[A], ys Seq[B]) Seq[R] {
+		return SeqOf(func() (r R, ok bool) {
+			x, ok := xs.Next()
+			if !ok {
+				_ = Close(ys)
+				return
+			}
+			y, ok := ys.Next()
+			if !ok {
+				_ = Close(xs)
+				return
+			}
+			return f(x, y), true
+		})
+	})
+}
+
+func Zip3[A, B, C, R any](xs Seq[A], ys Seq[B], zs Seq[C], f func(A, B, C) R) Seq[R] {
+	return Zip(Zip(xs, ys, MakeCons[A, B]), zs, func(xy Cons[A, B], z C) R {
+		return f(xy.Car, xy.Cdr, z)
+	})
+}
+
+// ZipLongest stops at the end of the longer one, pads the shorter one with Nothing
+func ZipLongest[A, B, R any](xs Seq[A], ys Seq[B], f func(maybe.Maybe[A], maybe.Maybe[B]) R) Seq[R] {
+	return lift2(xs, ys, func(xs Seq[A], ys Seq[B]) Seq[R] {
+		return SeqOf(func() (r R, ok bool) {
+			x := maybe.Of(xs.Next())
+			y := maybe.Of(ys.Next())
+			if !x.Just && !y.Just || Err(xs) != nil || Err(ys) != nil {
+				return
+			}
+			return f(x, y), true
+		})
+	})
+}
+
+// Unzip aka ToSlice for pairs
+func Unzip[A, B any](xs Seq[Cons[A, B]]) (as []A, bs []B) {
+	Iterate(xs, func(x Cons[A, B]) {
+		as = append(as, x.Car)
+		bs = append(bs, x.Cdr)
+	})
+	return
+}
+
+// UnzipSeq buffers the elements of one side until the other side takes them
+func UnzipSeq[A, B any](xs Seq[Cons[A, B]]) (Seq[A], Seq[B]) {
+	if _, ok := unwrap(xs).(Enumerable[Cons[A, B]]); ok {
+		return Select(xs, func(x Cons[A, B]) A { return x.Car }),
+			Select(xs, func(x Cons[A, B]) B { return x.Cdr })
+	}
+	var as []A
+	var bs []B
+	pull := func() bool {
+		x, ok := xs.Next()
+		if ok {
+			as = append(as, x.Car)
+			bs = append(bs, x.Cdr)
+		}
+		return ok
+	}
+	ys := SeqOf(func() (a A, ok bool) {
+		if len(as) == 0 && !pull() {
+			return
+		}
+		a, as = as[0], as[1:]
+		return a, true
+	})
+	zs := SeqOf(func() (b B, ok bool) {
+		if len(bs) == 0 && !pull() {
+			return
+		}
+		b, bs = bs[0], bs[1:]
+		return b, true
+	})
+	return inherit(ys, xs), inherit(zs, xs)
+}
+
+// Chunk splits into slices of size n, the last one may be shorter.
+// The yielded slice is reused by the next Next, Select Clone to keep it.
+func Chunk[A any](xs Seq[A], n int) Seq[[]A] {
+	if n <= 0 {
+		panic("linq: chunk size must be positive")
+	}
+	return lift(xs, func(xs Seq[A]) Seq[[]A] {
+		var buf []A
+		return SeqOf(func() ([]A, bool) {
+			if buf == nil {
+				buf = make([]A, 0, n)
+			}
+			buf = buf[:0]
+			for len(buf) < n {
+				x, ok := xs.Next()
+				if !ok {
+					if Err(xs) != nil {
+						return nil, false
+					}
+					break
+				}
+				buf = append(buf, x)
+			}
+			return buf, len(buf) > 0
+		})
+	})
+}
+
+// Window slides a window of size over xs, moving step elements each time,
+// partial windows are dropped.
+// The yielded slice is reused by the next Next, Select Clone to keep it.
+func Window[A any](xs Seq[A], size, step int) Seq[[]A] {
+	if size <= 0 || step <= 0 {
+		panic("linq: window size and step must be positive")
+	}
+	return lift(xs, func(xs Seq[A]) Seq[[]A] {
+		// each element is written twice, at i and i+size,
+		// so that the window is always contiguous in buf[head:head+size]
+		var buf []A
+		head, n, end := 0, 0, false
+		return SeqOf(func() (w []A, ok bool) {
+			if end {
+				return
+			}
+			if buf == nil {
+				buf = make([]A, 2*size)
+			} else if step < size {
+				n -= step
+			} else {
+				n = 0
+				for i := size; i < step; i++ {
+					if _, ok = xs.Next(); !ok {
+						end = true
+						return
+					}
+				}
+			}
+			for n < size {
+				var x A
+				if x, ok = xs.Next(); !ok {
+					end = true
+					return
+				}
+				buf[head], buf[head+size] = x, x
+				head, n = (head+1)%size, n+1
+			}
+			return buf[head : head+size], true
+		})
+	})
+}
+
+func Pairwise[A any](xs Seq[A]) Seq[Cons[A, A]] {
+	return Select(Window(xs, 2, 1), func(w []A) Cons[A, A] {
+		return Cons[A, A]{w[0], w[1]}
+	})
+}
+
+// ToSet ignores the error of xs like ToSlice, see ToSetE
+func ToSet[T comparable](xs Seq[T]) map[T]bool {
+	set, _ := toSet(xs)
+	return set
+}
+
+func toSet[T comparable](xs Seq[T]) (map[T]bool, error) {
+	set := map[T]bool{}
+	err := IterateE(xs, func(x T) error {
+		set[x] = true
+		return nil
+	})
+	return set, err
+}
+
+var ErrDuplicateKey = errors.New("linq: duplicate key")
+
+// OnDup resolves the values of a duplicate key, see DupError, KeepFirst, KeepLast, Merge
+type OnDup[V any] func(prev, cur V) (V, error)
+
+func DupError[V any](prev, _ V) (V, error) { return prev, ErrDuplicateKey }
+
+func KeepFirst[V any](prev, _ V) (V, error) { return prev, nil }
+
+func KeepLast[V any](_, cur V) (V, error) { return cur, nil }
+
+func Merge[V any](f func(prev, cur V) V) OnDup[V] {
+	return func(prev, cur V) (V, error) {
+		return f(prev, cur), nil
+	}
+}
+
+func ToMap[T any, K comparable, V any](
+	xs Seq[T],
+	key Selector[T, K],
+	val Selector[T, V],
+	onDup OnDup[V],
+) (m map[K]V, err error) {
+	m = map[K]V{}
+	xs = enumerator(xs)
+	for {
+		x, ok := xs.Next()
+		if !ok {
+			if err = Err(xs); err != nil {
+				return nil, err
+			}
+			return
+		}
+		k, v := key(x), val(x)
+		if prev, dup := m[k]; dup {
+			if v, err = onDup(prev, v); err != nil {
+				_ = Close(xs)
+				return nil, fmt.Errorf("%w: %v", err, k)
+			}
+		}
+		m[k] = v
+	}
+}
+
+// ToMultiMap ignores the error of xs, see ToMultiMapE
+func ToMultiMap[T any, K comparable, V any](xs Seq[T], key Selector[T, K], val Selector[T, V]) map[K][]V {
+	l, _ := toLookup(xs, key, val)
+	return l.vals
+}
+
+// ToLookup ignores the error of xs, see ToLookupE
+func ToLookup[T any, K comparable, V any](xs Seq[T], key Selector[T, K], val Selector[T, V]) *Lookup[K, V] {
+	l, _ := toLookup(xs, key, val)
+	return l
+}
+
+var ErrOverflow = errors.New("linq: integer overflow")
+
+// Sum of empty sequence is 0, it ignores the error of xs, see SumE
+func Sum[N Number](xs Seq[N]) N {
+	return Fold(xs, 0, func(acc N, cur N) N {
+		return acc + cur
+	})
+}
+
+// SumChecked reports ErrOverflow instead of wrapping silently, or the error of xs
+func SumChecked[N Integer](xs Seq[N]) (sum N, err error) {
+	xs = enumerator(xs)
+	for {
+		x, ok := xs.Next()
+		if !ok {
+			return sum, Err(xs)
+		}
+		r := sum + x
+		if x > 0 && r < sum || x < 0 && r > sum {
+			_ = Close(xs)
+			return sum, ErrOverflow
+		}
+		sum = r
+	}
+}
+
+// Average ignores the error of xs, see AverageE
+func Average[N Number](xs Seq[N]) (avg float64, ok bool) {
+	var sum float64
+	cnt := 0
+	Iterate(xs, func(x N) {
+		sum += float64(x)
+		cnt++
+	})
+	if cnt == 0 {
+		return
+	}
+	return sum / float64(cnt), true
+}
+
+func Min[A Ordered](xs Seq[A]) (A, bool) {
+	return MinBy(xs, Id[A])
+}
+
+func Max[A Ordered](xs Seq[A]) (A, bool) {
+	return MaxBy(xs, Id[A])
+}
+
+// MinBy returns the first element with the minimum key
+func MinBy[A any, K Ordered](xs Seq[A], key Selector[A, K]) (A, bool) {
+	return extremeBy(xs, key, func(k, min K) bool { return k < min })
+}
+
+// MaxBy returns the first element with the maximum key
+func MaxBy[A any, K Ordered](xs Seq[A], key Selector[A, K]) (A, bool) {
+	return extremeBy(xs, key, func(k, max K) bool { return k > max })
+}
+
+func extremeBy[A any, K Ordered](xs Seq[A], key Selector[A, K], better func(k, cur K) bool) (r A, ok bool) {
+	xs = enumerator(xs)
+	r, ok = xs.Next()
+	if !ok {
+		return
+	}
+	rk := key(r)
+	Iterate(xs, func(x A) {
+		if k := key(x); better(k, rk) {
+			r, rk = x, k
+		}
+	})
+	return
+}
+
+// ↓↓↓↓↓↓ Error ↓↓↓↓↓↓
+
+// SelectE stops at the first error of f, which is reported by Err
+func SelectE[A, R any](xs Seq[A], f func(A) (R, error)) Seq[R] {
+	return lift(xs, func(xs Seq[A]) Seq[R] {
+		var err error
+		return &wrapSeq[R]{
+			Seq: SeqOf(func() (r R, ok bool) {
+				if err != nil {
+					return
+				}
+				var x A
+				if x, ok = xs.Next(); !ok {
+					return
+				}
+				if r, err = f(x); err != nil {
+					_ = Close(xs)
+					var zero R
+					return zero, false
+				}
+				return
+			}),
+			err: func() error { return err },
+		}
+	})
+}
+
+// WhereE stops at the first error of p, which is reported by Err
+func WhereE[A any](xs Seq[A], p func(A) (bool, error)) Seq[A] {
+	return Bind(SelectE(xs, func(x A) (Seq[A], error) {
+		ok, err := p(x)
+		if !ok || err != nil {
+			return nil, err
+		}
+		return Return(x), nil
+	}), Id[Seq[A]])
+}
+
+// IterateE stops at the first error of xs or f
+func IterateE[T any](xs Seq[T], f func(T) error) error {
+	xs = enumerator(xs)
+	for {
+		x, ok := xs.Next()
+		if !ok {
+			return Err(xs)
+		}
+		if err := f(x); err != nil {
+			_ = Close(xs)
+			return err
+		}
+	}
+}
+
+func ToSliceE[T any](xs Seq[T]) (ys []T, err error) {
+	err = IterateE(xs, func(x T) error {
+		ys = append(ys, x)
+		return nil
+	})
+	return
+}
+
+func FoldE[A, R any](xs Seq[A], init R, f func(acc R, cur A) R) (acc R, err error) {
+	acc = init
+	err = IterateE(xs, func(x A) error {
+		acc = f(acc, x)
+		return nil
+	})
+	return
+}
+
+func AggregateE[A, B, R any](
+	xs Seq[A],
+	init B,
+	f func(acc B, cur A) B,
+	selector Selector[B, R],
+) (r R, err error) {
+	acc, err := FoldE(xs, init, f)
+	if err != nil {
+		return
+	}
+	return selector(acc), nil
+}
+
+func ToSetE[T comparable](xs Seq[T]) (map[T]bool, error) {
+	return toSet(xs)
+}
+
+func ToMultiMapE[T any, K comparable, V any](xs Seq[T], key Selector[T, K], val Selector[T, V]) (map[K][]V, error) {
+	l, err := toLookup(xs, key, val)
+	return l.vals, err
+}
+
+func ToLookupE[T any, K comparable, V any](xs Seq[T], key Selector[T, K], val Selector[T, V]) (*Lookup[K, V], error) {
+	return toLookup(xs, key, val)
+}
+
+func ReduceE[A any](xs Seq[A], f func(acc A, cur A) A) (r A, ok bool, err error) {
+	err = IterateE(xs, func(x A) error {
+		if ok {
+			r = f(r, x)
+		} else {
+			r, ok = x, true
+		}
+		return nil
+	})
+	return
+}
+
+func CountE[A any](xs Seq[A]) (int, error) {
+	if s, ok := indexedOf(xs); ok {
+		return s.Len(), nil
+	}
+	return FoldE(xs, 0, func(n int, _ A) int { return n + 1 })
+}
+
+func SumE[N Number](xs Seq[N]) (N, error) {
+	return FoldE(xs, 0, func(acc N, cur N) N {
+		return acc + cur
+	})
+}
+
+func AverageE[N Number](xs Seq[N]) (avg float64, ok bool, err error) {
+	var sum float64
+	cnt := 0
+	err = IterateE(xs, func(x N) error {
+		sum += float64(x)
+		cnt++
+		return nil
+	})
+	if err != nil || cnt == 0 {
+		return
+	}
+	return sum / float64(cnt), true, nil
+}
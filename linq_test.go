@@ -1,27 +1,23 @@
 package linq
 
 import (
+	"errors"
+	"io"
+	"maps"
+	"math"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/goghcrow/go-linq-object/maybe"
 )
 
 // tests ref
 // https://learn.microsoft.com/en-us/dotnet/api/system.linq.enumerable.aggregate?view=net-8.0
 
-type (
-	Signed interface {
-		~int | ~int8 | ~int16 | ~int32 | ~int64
-	}
-	Unsigned interface {
-		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
-	}
-	Integer interface{ Signed | Unsigned }
-	Float   interface{ ~float32 | ~float64 }
-	Number  interface{ Integer | Float }
-)
-
 func eq[A comparable](n A) Pred[A] { return func(x A) bool { return x == n } }
 func lt[N Number](n N) Pred[N]     { return func(x N) bool { return x < n } }
 func gt[N Number](n N) Pred[N]     { return func(x N) bool { return x > n } }
@@ -61,6 +57,32 @@ func TestOnce(t *testing.T) {
 		assertEqual(t, len(ToSlice(zs)), 0)
 	}
 
+	{
+		type T = Cons[string, int]
+		xs := From("a", "b", "c")
+		ys := From(1, 2, 3)
+		zs := SelectMany(xs, func(x string) Seq[T] {
+			return SelectMany(ys, func(y int) Seq[T] {
+				return Return(T{x, y})
+			})
+		})
+		assertEqual(t, ToSlice(zs), []T{
+			{"a", 1}, T{"a", 2}, T{"a", 3},
+		})
+	}
+}
+
+func TestEnumerable(t *testing.T) {
+	{
+		xs := FromSlice([]int{1, 2, 3})
+		ys := Select(xs, Id[int])
+		assertEqual(t, ToSlice(ys), []int{1, 2, 3})
+		assertEqual(t, ToSlice(ys), []int{1, 2, 3})
+
+		zs := Select(xs, Id[int])
+		assertEqual(t, ToSlice(zs), []int{1, 2, 3})
+	}
+
 	{
 		type T = Cons[string, int]
 		xs := FromSlice([]string{"a", "b", "c"})
@@ -72,8 +94,51 @@ func TestOnce(t *testing.T) {
 		})
 		assertEqual(t, ToSlice(zs), []T{
 			{"a", 1}, T{"a", 2}, T{"a", 3},
+			{"b", 1}, T{"b", 2}, T{"b", 3},
+			{"c", 1}, T{"c", 2}, T{"c", 3},
 		})
 	}
+
+	{
+		// the state of operators is not shared between iterations
+		xs := Range(0, 10)
+		twice := func(ys Seq[int]) [][]int {
+			_, ok := ys.(Enumerable[int])
+			assertEqual(t, ok, true)
+			return [][]int{ToSlice(ys), ToSlice(ys)}
+		}
+		assertEqual(t, twice(Take(Skip(xs, 2), 3)), [][]int{{2, 3, 4}, {2, 3, 4}})
+		assertEqual(t, twice(SelectWithIndex(Take(xs, 2), func(x, i int) int { return x + i })),
+			[][]int{{0, 2}, {0, 2}})
+		assertEqual(t, twice(Distinct(Select(xs, func(x int) int { return x % 2 }))),
+			[][]int{{0, 1}, {0, 1}})
+		assertEqual(t, twice(Prepend(Append(Take(xs, 1), 9), 8)), [][]int{{8, 0, 9}, {8, 0, 9}})
+		assertEqual(t, twice(Chain(Take(xs, 1), Take(xs, 1))), [][]int{{0, 0}, {0, 0}})
+		assertEqual(t, twice(Except(Take(xs, 3), FromSlice([]int{1}))), [][]int{{0, 2}, {0, 2}})
+		assertEqual(t, twice(Zip(xs, Take(xs, 2), func(x, y int) int { return x + y })),
+			[][]int{{0, 2}, {0, 2}})
+		assertEqual(t, twice(OrderByDescending(Take(xs, 3), Id[int])), [][]int{{2, 1, 0}, {2, 1, 0}})
+		assertEqual(t, twice(Take(Cycle(Take(xs, 2)), 3)), [][]int{{0, 1, 0}, {0, 1, 0}})
+		assertEqual(t, twice(Take(Unfold(1, func(s int) (int, int, bool) { return s, s * 2, true }), 3)),
+			[][]int{{1, 2, 4}, {1, 2, 4}})
+		assertEqual(t, twice(Select(Chunk(Take(xs, 3), 2), func(c []int) int { return Sum(FromSlice(c)) })), [][]int{{1, 2}, {1, 2}})
+		assertEqual(t, twice(Select(GroupBy(Take(xs, 3), isEven), func(g Grouping[bool, int]) int {
+			return len(ToSlice(g.Seq))
+		})), [][]int{{2, 1}, {2, 1}})
+	}
+
+	{
+		xs := FromSlice([]int{1, 2, 3})
+		assertEqual(t, Sum(xs), 6)
+		assertEqual(t, Sum(xs), 6)
+		assertEqual(t, slices.Collect(Values(xs)), slices.Collect(Values(xs)))
+	}
+
+	{
+		// single-pass in, single-pass out
+		_, ok := Select(From(1, 2, 3), Id[int]).(Enumerable[int])
+		assertEqual(t, ok, false)
+	}
 }
 
 func TestSelect(t *testing.T) {
@@ -410,6 +475,43 @@ func TestAppend(t *testing.T) {
 	)
 }
 
+func TestPrepend(t *testing.T) {
+	xs := Prepend(From(1, 2, 3), 0)
+	assertEqual(t, ToSlice(xs), []int{0, 1, 2, 3})
+}
+
+func TestConcat(t *testing.T) {
+	xs := Concat(From(1, 2), From(3, 4))
+	assertEqual(t, ToSlice(xs), []int{1, 2, 3, 4})
+
+	ys := Concat(From(1, 2), Range(3, math.MaxInt))
+	assertEqual(t, ToSlice(Take(ys, 4)), []int{1, 2, 3, 4})
+}
+
+func TestChain(t *testing.T) {
+	xs := Chain(From(1), nil, From[int](), From(2, 3))
+	assertEqual(t, ToSlice(xs), []int{1, 2, 3})
+	assertEqual(t, ToSlice(Chain[int]()), []int(nil))
+
+	_, ok := Concat(From(1), FromSlice([]int{2})).(Enumerable[int])
+	assertEqual(t, ok, false)
+	as, bs := UnzipSeq(Concat(From(Cons[int, string]{1, "a"}), From(Cons[int, string]{2, "b"})))
+	assertEqual(t, ToSlice(as), []int{1, 2})
+	assertEqual(t, ToSlice(bs), []string{"a", "b"})
+
+	// early stop closes every closable input
+	closed := 0
+	closable := func(xs ...int) Seq[int] {
+		return onClose(From(xs...), func() error { closed++; return nil })
+	}
+	x, _ := First(Skip(Chain(closable(1), closable(2, 3), closable(4)), 1))
+	assertEqual(t, x, 2)
+	assertEqual(t, closed, 3)
+	closed = 0
+	assertEqual(t, ToSlice(Take(SelectMany(From(1, 2, 3), func(x int) Seq[int] { return closable(x, x) }), 3)), []int{1, 1, 2})
+	assertEqual(t, closed, 2)
+}
+
 // left outer join
 func TestCrossJoin(t *testing.T) {
 	// from inner in items
@@ -433,3 +535,1098 @@ func TestCrossJoin(t *testing.T) {
 		{"c", 1}, T{"c", 2}, T{"c", 3},
 	})
 }
+
+func TestOrderBy(t *testing.T) {
+	type Pet struct {
+		Name string
+		Age  int
+	}
+	pets := []Pet{
+		{"Barley", 8},
+		{"Boots", 4},
+		{"Whiskers", 1},
+		{"Daisy", 4},
+	}
+	petName := func(pet Pet) string { return pet.Name }
+	petAge := func(pet Pet) int { return pet.Age }
+
+	{
+		xs := OrderBy(FromSlice(pets), petAge)
+		assertEqual(t, ToSlice(Select[Pet](xs, petName)), []string{
+			"Whiskers", "Boots", "Daisy", "Barley",
+		})
+	}
+	{
+		xs := OrderByDescending(FromSlice(pets), petAge)
+		assertEqual(t, ToSlice(Select[Pet](xs, petName)), []string{
+			"Barley", "Boots", "Daisy", "Whiskers",
+		})
+	}
+	{
+		xs := ThenByDescending(OrderBy(FromSlice(pets), petAge), petName)
+		assertEqual(t, ToSlice(Select[Pet](xs, petName)), []string{
+			"Whiskers", "Daisy", "Boots", "Barley",
+		})
+	}
+	{
+		xs := ThenBy(OrderByDescending(FromSlice(pets), petAge), petName)
+		assertEqual(t, ToSlice(Select[Pet](xs, petName)), []string{
+			"Barley", "Boots", "Daisy", "Whiskers",
+		})
+	}
+	// re-enumerable only if the source is
+	{
+		xs := OrderBy(FromSlice(pets), petAge)
+		assertEqual(t, ToSlice(Select[Pet](xs, petName)), ToSlice(Select[Pet](xs, petName)))
+		ys := OrderBy(From(pets...), petAge)
+		_, ok := ys.(Enumerable[Pet])
+		assertEqual(t, ok, false)
+		assertEqual(t, ToSlice(Select[Pet](ys, petName)), []string{
+			"Whiskers", "Boots", "Daisy", "Barley",
+		})
+		assertEqual(t, ToSlice(Select[Pet](ys, petName)), []string(nil))
+
+		type T = Cons[int, string]
+		as, bs := UnzipSeq(OrderBy(From(T{2, "b"}, T{1, "a"}), func(x T) int { return x.Car }))
+		assertEqual(t, ToSlice(as), []int{1, 2})
+		assertEqual(t, ToSlice(bs), []string{"a", "b"})
+	}
+}
+
+func TestOrderByFunc(t *testing.T) {
+	type Pet struct {
+		Name string
+		Age  int
+	}
+	pets := []Pet{{"Barley", 8}, {"Boots", 4}, {"Whiskers", 1}, {"Daisy", 4}}
+	byAge := func(a, b Pet) int { return a.Age - b.Age }
+	byName := func(a, b Pet) int { return strings.Compare(a.Name, b.Name) }
+	petName := func(pet Pet) string { return pet.Name }
+
+	xs := ThenByDescendingFunc(OrderByFunc(FromSlice(pets), byAge), byName)
+	assertEqual(t, ToSlice(Select[Pet](xs, petName)), []string{"Whiskers", "Daisy", "Boots", "Barley"})
+	xs = ThenByFunc(OrderByDescendingFunc(FromSlice(pets), byAge), byName)
+	assertEqual(t, ToSlice(Select[Pet](xs, petName)), []string{"Barley", "Boots", "Daisy", "Whiskers"})
+}
+
+func TestOrderByDeferred(t *testing.T) {
+	xs := SeqOf[int](func() (int, bool) {
+		panic("deferred")
+	})
+	_ = ThenBy(OrderBy(xs, Id[int]), double)
+}
+
+func TestGroupBy(t *testing.T) {
+	type Pet struct {
+		Name string
+		Age  int
+	}
+	pets := []Pet{
+		{"Barley", 8},
+		{"Boots", 4},
+		{"Whiskers", 1},
+		{"Daisy", 4},
+	}
+	petName := func(pet Pet) string { return pet.Name }
+	petAge := func(pet Pet) int { return pet.Age }
+
+	{
+		gs := ToSlice(GroupBy(FromSlice(pets), petAge))
+		assertEqual(t, len(gs), 3)
+		assertEqual(t, gs[0].Key, 8)
+		assertEqual(t, ToSlice(gs[0].Seq), []Pet{{"Barley", 8}})
+		assertEqual(t, gs[1].Key, 4)
+		assertEqual(t, ToSlice[Pet](gs[1]), []Pet{{"Boots", 4}, {"Daisy", 4}})
+		assertEqual(t, gs[2].Key, 1)
+	}
+	{
+		gs := ToSlice(GroupByWithElement(FromSlice(pets), petAge, petName))
+		assertEqual(t, gs[1].Key, 4)
+		assertEqual(t, ToSlice(gs[1].Seq), []string{"Boots", "Daisy"})
+	}
+	{
+		type T = Cons[int, int]
+		xs := GroupByWithResult(FromSlice(pets), petAge, func(age int, pets Seq[Pet]) T {
+			return T{age, len(ToSlice(pets))}
+		})
+		assertEqual(t, ToSlice(xs), []T{{8, 1}, {4, 2}, {1, 1}})
+	}
+}
+
+func TestGroupByDeferred(t *testing.T) {
+	xs := SeqOf[int](func() (int, bool) {
+		panic("deferred")
+	})
+	_ = GroupBy(xs, isEven)
+}
+
+func TestJoin(t *testing.T) {
+	type Person struct {
+		Name string
+	}
+	type Pet struct {
+		Name  string
+		Owner string
+	}
+	people := []Person{{"Hedlund"}, {"Adams"}, {"Weiss"}}
+	pets := []Pet{
+		{"Barley", "Adams"},
+		{"Boots", "Adams"},
+		{"Whiskers", "Weiss"},
+		{"Daisy", "Rover"},
+	}
+	personName := func(p Person) string { return p.Name }
+	petOwner := func(p Pet) string { return p.Owner }
+
+	type T = Cons[string, string]
+	{
+		xs := Join(FromSlice(people), FromSlice(pets), personName, petOwner,
+			func(person Person, pet Pet) T {
+				return T{person.Name, pet.Name}
+			})
+		assertEqual(t, ToSlice(xs), []T{
+			{"Adams", "Barley"}, {"Adams", "Boots"}, {"Weiss", "Whiskers"},
+		})
+	}
+	{
+		type G = Cons[string, []string]
+		xs := GroupJoin(FromSlice(people), FromSlice(pets), personName, petOwner,
+			func(person Person, pets Seq[Pet]) G {
+				return G{person.Name, ToSlice(Select(pets, func(p Pet) string { return p.Name }))}
+			})
+		assertEqual(t, ToSlice(xs), []G{
+			{"Hedlund", nil}, {"Adams", []string{"Barley", "Boots"}}, {"Weiss", []string{"Whiskers"}},
+		})
+	}
+
+	orNone := func(m maybe.Maybe[string]) string {
+		if m.Just {
+			return m.Value
+		}
+		return "-"
+	}
+	{
+		xs := LeftJoin(FromSlice(people), FromSlice(pets), personName, petOwner,
+			func(person Person, pet maybe.Maybe[Pet]) T {
+				return T{person.Name, orNone(maybe.Map(pet, func(p Pet) string { return p.Name }))}
+			})
+		assertEqual(t, ToSlice(xs), []T{
+			{"Hedlund", "-"}, {"Adams", "Barley"}, {"Adams", "Boots"}, {"Weiss", "Whiskers"},
+		})
+	}
+	{
+		xs := RightJoin(FromSlice(people), FromSlice(pets), personName, petOwner,
+			func(person maybe.Maybe[Person], pet Pet) T {
+				return T{orNone(maybe.Map(person, personName)), pet.Name}
+			})
+		assertEqual(t, ToSlice(xs), []T{
+			{"Adams", "Barley"}, {"Adams", "Boots"}, {"Weiss", "Whiskers"}, {"-", "Daisy"},
+		})
+	}
+	{
+		xs := FullOuterJoin(FromSlice(people), FromSlice(pets), personName, petOwner,
+			func(person maybe.Maybe[Person], pet maybe.Maybe[Pet]) T {
+				return T{
+					orNone(maybe.Map(person, personName)),
+					orNone(maybe.Map(pet, func(p Pet) string { return p.Name })),
+				}
+			})
+		assertEqual(t, ToSlice(xs), []T{
+			{"Hedlund", "-"}, {"Adams", "Barley"}, {"Adams", "Boots"}, {"Weiss", "Whiskers"}, {"-", "Daisy"},
+		})
+	}
+}
+
+func TestJoinDeferred(t *testing.T) {
+	xs := SeqOf[int](func() (int, bool) {
+		panic("deferred")
+	})
+	_ = Join(xs, xs, Id[int], Id[int], func(x, y int) int { return x + y })
+	_ = FullOuterJoin(xs, xs, Id[int], Id[int], func(x, y maybe.Maybe[int]) int { return 0 })
+}
+
+func TestDistinct(t *testing.T) {
+	xs := From(21, 46, 46, 55, 17, 21, 55, 55)
+	assertEqual(t, ToSlice(Distinct(xs)), []int{21, 46, 55, 17})
+
+	ys := From("apple", "Avocado", "banana", "blueberry", "cherry")
+	firstChar := func(s string) string { return strings.ToLower(s[:1]) }
+	assertEqual(t, ToSlice(DistinctBy(ys, firstChar)), []string{"apple", "banana", "cherry"})
+}
+
+func TestUnion(t *testing.T) {
+	xs := From(5, 3, 9, 7, 5, 9, 3, 7)
+	ys := From(8, 3, 6, 4, 4, 9, 1, 0)
+	assertEqual(t, ToSlice(Union(xs, ys)), []int{5, 3, 9, 7, 8, 6, 4, 1, 0})
+
+	{
+		xs := From(1, 2, 3)
+		ys := From(-2, -4)
+		abs := func(x int) int {
+			if x < 0 {
+				return -x
+			}
+			return x
+		}
+		assertEqual(t, ToSlice(UnionBy(xs, ys, abs)), []int{1, 2, 3, -4})
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	xs := From(44, 26, 92, 30, 71, 38, 26)
+	ys := From(39, 59, 83, 47, 26, 4, 30)
+	assertEqual(t, ToSlice(Intersect(xs, ys)), []int{26, 30})
+
+	type Pet struct {
+		Name string
+		Age  int
+	}
+	pets := From(Pet{"Barley", 8}, Pet{"Boots", 4}, Pet{"Whiskers", 1})
+	petAge := func(pet Pet) int { return pet.Age }
+	assertEqual(t, ToSlice(IntersectBy(pets, From(1, 8), petAge)), []Pet{{"Barley", 8}, {"Whiskers", 1}})
+}
+
+func TestExcept(t *testing.T) {
+	xs := From(2.0, 2.0, 2.1, 2.2, 2.3, 2.3, 2.4, 2.5)
+	ys := From(2.2)
+	assertEqual(t, ToSlice(Except(xs, ys)), []float64{2.0, 2.1, 2.3, 2.4, 2.5})
+
+	type Pet struct {
+		Name string
+		Age  int
+	}
+	pets := From(Pet{"Barley", 8}, Pet{"Boots", 4}, Pet{"Whiskers", 1})
+	petAge := func(pet Pet) int { return pet.Age }
+	assertEqual(t, ToSlice(ExceptBy(pets, From(1, 8), petAge)), []Pet{{"Boots", 4}})
+}
+
+func TestSetDeferred(t *testing.T) {
+	xs := SeqOf[int](func() (int, bool) {
+		panic("deferred")
+	})
+	_ = Distinct(xs)
+	_ = Union(xs, xs)
+	_ = Intersect(xs, xs)
+	_ = Except(xs, xs)
+}
+
+func TestZip(t *testing.T) {
+	xs := From(1, 2, 3, 4)
+	ys := From("one", "two", "three")
+	zs := Zip(xs, ys, func(x int, y string) string {
+		return strconv.Itoa(x) + " " + y
+	})
+	assertEqual(t, ToSlice(zs), []string{"1 one", "2 two", "3 three"})
+
+	type T = Cons[int, string]
+	assertEqual(t, ToSlice(Zip(From(1, 2), From("a", "b", "c"), MakeCons[int, string])), []T{
+		{1, "a"}, {2, "b"},
+	})
+}
+
+func TestZip3(t *testing.T) {
+	xs := Zip3(From(1, 2, 3), From(10, 20), From(100, 200, 300), func(x, y, z int) int {
+		return x + y + z
+	})
+	assertEqual(t, ToSlice(xs), []int{111, 222})
+}
+
+func TestZipLongest(t *testing.T) {
+	type T = Cons[maybe.Maybe[int], maybe.Maybe[string]]
+	xs := ZipLongest(From(1, 2, 3), From("a"), MakeCons[maybe.Maybe[int], maybe.Maybe[string]])
+	assertEqual(t, ToSlice(xs), []T{
+		{maybe.Just(1), maybe.Just("a")},
+		{maybe.Just(2), maybe.Nothing[string]()},
+		{maybe.Just(3), maybe.Nothing[string]()},
+	})
+}
+
+func TestUnzip(t *testing.T) {
+	type T = Cons[int, string]
+	{
+		as, bs := Unzip(From(T{1, "a"}, T{2, "b"}))
+		assertEqual(t, as, []int{1, 2})
+		assertEqual(t, bs, []string{"a", "b"})
+	}
+	{
+		as, bs := UnzipSeq(From(T{1, "a"}, T{2, "b"}, T{3, "c"}))
+		assertEqual(t, ToSlice(bs), []string{"a", "b", "c"})
+		assertEqual(t, ToSlice(as), []int{1, 2, 3})
+	}
+}
+
+func TestChunk(t *testing.T) {
+	xs := Select(Chunk(Range(0, 8), 3), Clone[int])
+	assertEqual(t, ToSlice(xs), [][]int{{0, 1, 2}, {3, 4, 5}, {6, 7}})
+	assertEqual(t, ToSlice(Chunk(Range(0, 0), 3)), [][]int(nil))
+}
+
+func TestWindow(t *testing.T) {
+	{
+		xs := Select(Window(Range(0, 6), 3, 1), Clone[int])
+		assertEqual(t, ToSlice(xs), [][]int{{0, 1, 2}, {1, 2, 3}, {2, 3, 4}, {3, 4, 5}})
+	}
+	{
+		xs := Select(Window(Range(0, 8), 3, 2), Clone[int])
+		assertEqual(t, ToSlice(xs), [][]int{{0, 1, 2}, {2, 3, 4}, {4, 5, 6}})
+	}
+	{
+		xs := Select(Window(Range(0, 10), 2, 4), Clone[int])
+		assertEqual(t, ToSlice(xs), [][]int{{0, 1}, {4, 5}, {8, 9}})
+	}
+	{
+		xs := Window(Range(0, 2), 3, 1)
+		assertEqual(t, ToSlice(xs), [][]int(nil))
+	}
+}
+
+func TestPairwise(t *testing.T) {
+	type T = Cons[int, int]
+	xs := Pairwise(From(1, 2, 4, 7))
+	assertEqual(t, ToSlice(xs), []T{{1, 2}, {2, 4}, {4, 7}})
+	assertEqual(t, ToSlice(Pairwise(From(1))), []T(nil))
+}
+
+func TestToSet(t *testing.T) {
+	assertEqual(t, ToSet(From(1, 2, 2, 3)), map[int]bool{1: true, 2: true, 3: true})
+}
+
+func TestToMap(t *testing.T) {
+	type Pet struct {
+		Name string
+		Age  int
+	}
+	pets := []Pet{
+		{"Barley", 8},
+		{"Boots", 4},
+		{"Whiskers", 1},
+		{"Daisy", 4},
+	}
+	petName := func(pet Pet) string { return pet.Name }
+	petAge := func(pet Pet) int { return pet.Age }
+
+	{
+		m, err := ToMap(FromSlice(pets), petName, petAge, DupError[int])
+		assertEqual(t, err, nil)
+		assertEqual(t, m, map[string]int{"Barley": 8, "Boots": 4, "Whiskers": 1, "Daisy": 4})
+	}
+	{
+		m, err := ToMap(FromSlice(pets), petAge, petName, DupError[string])
+		assertEqual(t, errors.Is(err, ErrDuplicateKey), true)
+		assertEqual(t, err.Error(), "linq: duplicate key: 4")
+		assertEqual(t, m, map[int]string(nil))
+
+		closed := 0
+		xs := onClose(FromSlice(pets), func() error { closed++; return nil })
+		_, err = ToMap(xs, petAge, petName, DupError[string])
+		assertEqual(t, errors.Is(err, ErrDuplicateKey), true)
+		assertEqual(t, closed, 1)
+	}
+	{
+		m, _ := ToMap(FromSlice(pets), petAge, petName, KeepFirst[string])
+		assertEqual(t, m, map[int]string{8: "Barley", 4: "Boots", 1: "Whiskers"})
+	}
+	{
+		m, _ := ToMap(FromSlice(pets), petAge, petName, KeepLast[string])
+		assertEqual(t, m, map[int]string{8: "Barley", 4: "Daisy", 1: "Whiskers"})
+	}
+	{
+		m, _ := ToMap(FromSlice(pets), petAge, petName, Merge(func(prev, cur string) string {
+			return prev + "," + cur
+		}))
+		assertEqual(t, m, map[int]string{8: "Barley", 4: "Boots,Daisy", 1: "Whiskers"})
+	}
+}
+
+func TestToLookup(t *testing.T) {
+	type Pet struct {
+		Name string
+		Age  int
+	}
+	pets := []Pet{
+		{"Barley", 8},
+		{"Boots", 4},
+		{"Whiskers", 1},
+		{"Daisy", 4},
+	}
+	petName := func(pet Pet) string { return pet.Name }
+	petAge := func(pet Pet) int { return pet.Age }
+
+	assertEqual(t, ToMultiMap(FromSlice(pets), petAge, petName), map[int][]string{
+		8: {"Barley"}, 4: {"Boots", "Daisy"}, 1: {"Whiskers"},
+	})
+
+	l := ToLookup(FromSlice(pets), petAge, petName)
+	assertEqual(t, l.Len(), 3)
+	assertEqual(t, l.Contains(4), true)
+	assertEqual(t, l.Contains(5), false)
+	assertEqual(t, ToSlice(l.Get(4)), []string{"Boots", "Daisy"})
+	assertEqual(t, ToSlice(l.Get(5)), []string(nil))
+	assertEqual(t, ToSlice(l.Keys()), []int{8, 4, 1})
+	assertEqual(t, ToSlice(Select(l.Groups(), func(g Grouping[int, string]) int {
+		return g.Key
+	})), []int{8, 4, 1})
+}
+
+func TestSum(t *testing.T) {
+	assertEqual(t, Sum(From(43.68, 1.25, 583.7, 6.5)), 43.68+1.25+583.7+6.5)
+	assertEqual(t, Sum(From[int]()), 0)
+
+	{
+		sum, err := SumChecked(From[int8](100, 20, 7))
+		assertEqual(t, sum, int8(127))
+		assertEqual(t, err, nil)
+	}
+	{
+		sum, err := SumChecked(From[int8](100, 20, 8))
+		assertEqual(t, sum, int8(120))
+		assertEqual(t, err, ErrOverflow)
+	}
+	{
+		_, err := SumChecked(From[int8](-100, -20, -9))
+		assertEqual(t, err, ErrOverflow)
+	}
+	{
+		_, err := SumChecked(From[uint8](200, 56))
+		assertEqual(t, err, ErrOverflow)
+	}
+}
+
+func TestAverage(t *testing.T) {
+	avg, ok := Average(From(78, 92, 100, 37, 81))
+	assertEqual(t, avg, 77.6)
+	assertEqual(t, ok, true)
+
+	_, ok = Average(From[int]())
+	assertEqual(t, ok, false)
+}
+
+func TestMinMax(t *testing.T) {
+	xs := []int{4, 8, 8, 3, 9, 0, 7, 8, 2}
+	{
+		x, ok := Min(FromSlice(xs))
+		assertEqual(t, x, 0)
+		assertEqual(t, ok, true)
+	}
+	{
+		x, ok := Max(FromSlice(xs))
+		assertEqual(t, x, 9)
+		assertEqual(t, ok, true)
+	}
+	{
+		_, ok := Max(From[string]())
+		assertEqual(t, ok, false)
+	}
+}
+
+func TestMinByMaxBy(t *testing.T) {
+	type Pet struct {
+		Name string
+		Age  int
+	}
+	pets := []Pet{
+		{"Barley", 8},
+		{"Boots", 4},
+		{"Whiskers", 1},
+		{"Daisy", 8},
+	}
+	petAge := func(pet Pet) int { return pet.Age }
+	{
+		x, ok := MinBy(FromSlice(pets), petAge)
+		assertEqual(t, x, Pet{"Whiskers", 1})
+		assertEqual(t, ok, true)
+	}
+	{
+		x, ok := MaxBy(FromSlice(pets), petAge)
+		assertEqual(t, x, Pet{"Barley", 8})
+		assertEqual(t, ok, true)
+	}
+}
+
+func TestElementAt(t *testing.T) {
+	xs := []string{"Hartono", "Adams", "Andersen", "Hedlund"}
+	{
+		x, ok := ElementAt(FromSlice(xs), 2)
+		assertEqual(t, x, "Andersen")
+		assertEqual(t, ok, true)
+	}
+	{
+		_, ok := ElementAt(FromSlice(xs), 4)
+		assertEqual(t, ok, false)
+	}
+	{
+		_, ok := ElementAt(FromSlice(xs), -1)
+		assertEqual(t, ok, false)
+	}
+	assertEqual(t, ElementAtOrDefault(FromSlice(xs), 4, "none"), "none")
+	assertEqual(t, ElementAtMaybe(FromSlice(xs), 0), maybe.Just("Hartono"))
+}
+
+func TestOrDefault(t *testing.T) {
+	assertEqual(t, FirstOrDefault(From(1, 2), -1), 1)
+	assertEqual(t, FirstOrDefault(From[int](), -1), -1)
+	assertEqual(t, LastOrDefault(From(1, 2), -1), 2)
+	assertEqual(t, LastOrDefault(From[int](), -1), -1)
+}
+
+func TestSingle(t *testing.T) {
+	{
+		x, err := Single(From("Orange"))
+		assertEqual(t, x, "Orange")
+		assertEqual(t, err, nil)
+	}
+	{
+		_, err := Single(From[string]())
+		assertEqual(t, err, ErrNoElements)
+	}
+	{
+		_, err := Single(From("Orange", "Apple"))
+		assertEqual(t, err, ErrMultipleElements)
+	}
+	{
+		x, err := SingleWhile(From("apple", "banana", "mango"), func(s string) bool {
+			return len(s) > 5
+		})
+		assertEqual(t, x, "banana")
+		assertEqual(t, err, nil)
+	}
+	assertEqual(t, SingleMaybe(From(1, 2)), maybe.Nothing[int]())
+}
+
+func TestMaybe(t *testing.T) {
+	assertEqual(t, FirstMaybe(From(1, 2, 3)), maybe.Just(1))
+	assertEqual(t, FirstMaybe(From[int]()), maybe.Nothing[int]())
+	assertEqual(t, FirstWhileMaybe(From(1, 2, 3), gt(1)), maybe.Just(2))
+	assertEqual(t, LastMaybe(From(1, 2, 3)), maybe.Just(3))
+	assertEqual(t, LastWhileMaybe(From(1, 2, 3), gt(3)), maybe.Nothing[int]())
+
+	xs := From(1, 2)
+	ys := From(10, 20)
+	z := maybe.Bind(FirstMaybe(xs), func(x int) maybe.Maybe[int] {
+		return maybe.Map(LastMaybe(ys), func(y int) int {
+			return x + y
+		})
+	})
+	assertEqual(t, z, maybe.Just(21))
+}
+
+func TestRangeStep(t *testing.T) {
+	assertEqual(t, ToSlice(RangeStep(0, 10, 3)), []int{0, 3, 6, 9})
+	assertEqual(t, ToSlice(RangeStep(10, 0, -3)), []int{10, 7, 4, 1})
+	assertEqual(t, ToSlice(RangeStep(0, 0, 1)), []int(nil))
+	assertEqual(t, ToSlice(RangeStep(0, 10, -1)), []int(nil))
+}
+
+func TestNumberRange(t *testing.T) {
+	assertEqual(t, ToSlice(NumberRange(0, 1, 0.25)), []float64{0, 0.25, 0.5, 0.75})
+	assertEqual(t, len(ToSlice(NumberRange(0, 1, 0.1))), 10)
+	assertEqual(t, ToSlice(NumberRange[uint8](250, 255, 2)), []uint8{250, 252, 254})
+}
+
+func TestDateRange(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, 2, d, 0, 0, 0, 0, time.UTC)
+	}
+	march := func(d int) time.Time {
+		return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC)
+	}
+	assertEqual(t, ToSlice(DateRange(day(27), march(3), 48*time.Hour)),
+		[]time.Time{day(27), day(29), march(2)})
+	assertEqual(t, ToSlice(DateRange(day(3), day(1), -24*time.Hour)), []time.Time{day(3), day(2)})
+}
+
+func TestRepeat(t *testing.T) {
+	assertEqual(t, ToSlice(Repeat("a", 3)), []string{"a", "a", "a"})
+	assertEqual(t, ToSlice(Take(Infinite(42), 2)), []int{42, 42})
+}
+
+func TestGenerate(t *testing.T) {
+	i := 0
+	xs := Generate(func() int {
+		i++
+		return i * i
+	})
+	assertEqual(t, ToSlice(Take(xs, 4)), []int{1, 4, 9, 16})
+}
+
+func TestUnfold(t *testing.T) {
+	type T = Cons[int, int]
+	fib := Unfold(T{0, 1}, func(s T) (int, T, bool) {
+		return s.Car, T{s.Cdr, s.Car + s.Cdr}, true
+	})
+	assertEqual(t, ToSlice(Take(fib, 8)), []int{0, 1, 1, 2, 3, 5, 8, 13})
+
+	digits := Unfold(1234, func(n int) (int, int, bool) {
+		return n % 10, n / 10, n > 0
+	})
+	assertEqual(t, ToSlice(digits), []int{4, 3, 2, 1})
+}
+
+func TestCycle(t *testing.T) {
+	assertEqual(t, ToSlice(Take(Cycle(From(1, 2, 3)), 7)), []int{1, 2, 3, 1, 2, 3, 1})
+	assertEqual(t, ToSlice(Take(Cycle(From[int]()), 7)), []int(nil))
+}
+
+func TestValues(t *testing.T) {
+	var ys []int
+	for x := range Values(Range(0, 10)) {
+		if x > 3 {
+			break
+		}
+		ys = append(ys, x)
+	}
+	assertEqual(t, ys, []int{0, 1, 2, 3})
+	assertEqual(t, slices.Collect(Values(From(1, 2, 3))), []int{1, 2, 3})
+}
+
+func TestEnumerate(t *testing.T) {
+	var ys []string
+	for i, x := range Enumerate(From("a", "b")) {
+		ys = append(ys, strconv.Itoa(i)+x)
+	}
+	assertEqual(t, ys, []string{"0a", "1b"})
+}
+
+func TestPairs(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2}
+	assertEqual(t, maps.Collect(Pairs(FromMap(m))), m)
+}
+
+func TestFromIter(t *testing.T) {
+	xs := FromIter(slices.Values([]int{1, 2, 3, 4}))
+	assertEqual(t, ToSlice(Select(xs, square)), []int{1, 4, 9, 16})
+	_, ok := xs.Next()
+	assertEqual(t, ok, false)
+
+	stopped := false
+	ys := FromIter(func(yield func(int) bool) {
+		defer func() { stopped = true }()
+		for i := 0; yield(i); i++ {
+		}
+	})
+	_, _ = ys.Next()
+	assertEqual(t, stopped, false)
+	_ = ys.(io.Closer).Close()
+	assertEqual(t, stopped, true)
+
+	m := map[string]int{"a": 1}
+	assertEqual(t, ToSlice(FromIter2(maps.All(m))), []Cons[string, int]{{"a", 1}})
+}
+
+type resource struct {
+	name string
+	log  *[]string
+}
+
+func (r *resource) Close() error {
+	*r.log = append(*r.log, "close "+r.name)
+	return nil
+}
+
+func TestUsing(t *testing.T) {
+	var log []string
+	open := func(name string) func() *resource {
+		return func() *resource {
+			log = append(log, "open "+name)
+			return &resource{name, &log}
+		}
+	}
+	body := func(r *resource) Seq[int] {
+		return Range(0, 5)
+	}
+
+	{
+		log = nil
+		xs := Using(open("a"), body)
+		assertEqual(t, log, []string(nil))
+		assertEqual(t, ToSlice(xs), []int{0, 1, 2, 3, 4})
+		assertEqual(t, log, []string{"open a", "close a"})
+		assertEqual(t, ToSlice(xs), []int{0, 1, 2, 3, 4})
+		assertEqual(t, log, []string{"open a", "close a", "open a", "close a"})
+	}
+	{
+		log = nil
+		xs := Take(Select(Using(open("a"), body), square), 2)
+		assertEqual(t, ToSlice(xs), []int{0, 1})
+		assertEqual(t, log, []string{"open a", "close a"})
+	}
+	{
+		log = nil
+		x, ok := First(Using(open("a"), body))
+		assertEqual(t, x, 0)
+		assertEqual(t, ok, true)
+		assertEqual(t, log, []string{"open a", "close a"})
+	}
+	{
+		log = nil
+		_, _ = FirstWhile(Using(open("a"), body), gt(1))
+		assertEqual(t, Any(Using(open("b"), body), gt(1)), true)
+		assertEqual(t, All(Using(open("c"), body), lt(1)), false)
+		assertEqual(t, AnyElem(Using(open("d"), body)), true)
+		assertEqual(t, log, []string{
+			"open a", "close a", "open b", "close b",
+			"open c", "close c", "open d", "close d",
+		})
+	}
+	{
+		log = nil
+		xs := Zip(Using(open("a"), body), Concat(Using(open("b"), body), Using(open("c"), body)),
+			func(x, y int) int { return x + y })
+		assertEqual(t, ToSlice(xs), []int{0, 2, 4, 6, 8})
+		assertEqual(t, log, []string{"open a", "open b", "close a", "close b"})
+	}
+	{
+		log = nil
+		for x := range Values(Using(open("a"), body)) {
+			if x == 1 {
+				break
+			}
+		}
+		assertEqual(t, log, []string{"open a", "close a"})
+	}
+	{
+		log = nil
+		xs := Where(Using(open("a"), body), isEven)
+		_, _ = xs.Next()
+		assertEqual(t, Close(xs), nil)
+		assertEqual(t, Close(xs), nil)
+		assertEqual(t, log, []string{"open a", "close a"})
+	}
+}
+
+func TestDefer(t *testing.T) {
+	calls := 0
+	xs := Defer(func() Seq[int] {
+		calls++
+		return From(1, 2, 3)
+	})
+	assertEqual(t, calls, 0)
+	assertEqual(t, ToSlice(xs), []int{1, 2, 3})
+	assertEqual(t, ToSlice(xs), []int{1, 2, 3})
+	assertEqual(t, calls, 2)
+}
+
+func TestSeqE(t *testing.T) {
+	errBoom := errors.New("boom")
+	// 0, 1, 2, then boom
+	failing := func() Seq[int] {
+		i := 0
+		return SeqOfE(func() (int, bool, error) {
+			if i == 3 {
+				return 0, false, errBoom
+			}
+			i++
+			return i - 1, true, nil
+		})
+	}
+
+	{
+		xs := failing()
+		assertEqual(t, ToSlice(xs), []int{0, 1, 2})
+		assertEqual(t, Err(xs), errBoom)
+		_, ok := xs.Next()
+		assertEqual(t, ok, false)
+	}
+	{
+		ys, err := ToSliceE(Select(Where(failing(), isEven), double))
+		assertEqual(t, ys, []int{0, 4})
+		assertEqual(t, err, errBoom)
+	}
+	{
+		ys, err := ToSliceE(Take(failing(), 2))
+		assertEqual(t, ys, []int{0, 1})
+		assertEqual(t, err, nil)
+	}
+
+	each := []Seq[int]{
+		Skip(failing(), 1),
+		Append(failing(), 9),
+		Concat(From(1), failing()),
+		Concat(failing(), From(1)),
+		SelectMany(From(1, 2), func(int) Seq[int] { return failing() }),
+		Distinct(failing()),
+		Except(FromSlice([]int{1}), failing()),
+		OrderBy(failing(), Id[int]),
+		Select(GroupBy(failing(), isEven), func(g Grouping[bool, int]) int { return 0 }),
+		Join(From(1), failing(), Id[int], Id[int], func(x, y int) int { return x }),
+		Zip(failing(), Range(0, 10), func(x, y int) int { return x }),
+		ZipLongest(failing(), Range(0, 10), func(x, y maybe.Maybe[int]) int { return 0 }),
+		Select(Chunk(failing(), 2), func(c []int) int { return len(c) }),
+		Take(Cycle(failing()), 10),
+		Defer(failing),
+	}
+	for _, xs := range each {
+		_, err := ToSliceE(xs)
+		assertEqual(t, err, errBoom)
+	}
+
+	{
+		_, err := FoldE(failing(), 0, func(acc, cur int) int { return acc + cur })
+		assertEqual(t, err, errBoom)
+		_, err = AggregateE(failing(), 0, func(acc, cur int) int { return acc + cur }, strconv.Itoa)
+		assertEqual(t, err, errBoom)
+		_, _, err = ReduceE(failing(), func(acc, cur int) int { return acc + cur })
+		assertEqual(t, err, errBoom)
+		_, err = ToMap(failing(), Id[int], Id[int], DupError[int])
+		assertEqual(t, err, errBoom)
+		sum, err := SumChecked(failing())
+		assertEqual(t, sum, 3)
+		assertEqual(t, err, errBoom)
+		set, err := ToSetE(failing())
+		assertEqual(t, set, map[int]bool{0: true, 1: true, 2: true})
+		assertEqual(t, err, errBoom)
+		_, err = ToMultiMapE(failing(), isEven, Id[int])
+		assertEqual(t, err, errBoom)
+		_, err = ToLookupE(failing(), isEven, Id[int])
+		assertEqual(t, err, errBoom)
+		n, err := CountE(failing())
+		assertEqual(t, n, 3)
+		assertEqual(t, err, errBoom)
+		sum, err = SumE(failing())
+		assertEqual(t, sum, 3)
+		assertEqual(t, err, errBoom)
+		_, ok, err := AverageE(failing())
+		assertEqual(t, ok, false)
+		assertEqual(t, err, errBoom)
+	}
+	{
+		r, err := FoldE(From(1, 2, 3), 0, func(acc, cur int) int { return acc + cur })
+		assertEqual(t, r, 6)
+		assertEqual(t, err, nil)
+		r, ok, err := ReduceE(From(1, 2, 3), func(acc, cur int) int { return acc * cur })
+		assertEqual(t, r, 6)
+		assertEqual(t, ok, true)
+		assertEqual(t, err, nil)
+		n, err := CountE(FromSlice([]int{1, 2, 3}))
+		assertEqual(t, n, 3)
+		assertEqual(t, err, nil)
+		r, err = SumE(From(1, 2, 3))
+		assertEqual(t, r, 6)
+		assertEqual(t, err, nil)
+		avg, ok, err := AverageE(From(1, 2, 3))
+		assertEqual(t, avg, 2.0)
+		assertEqual(t, ok, true)
+		assertEqual(t, err, nil)
+	}
+}
+
+func TestSelectE(t *testing.T) {
+	{
+		xs := SelectE(From("1", "2", "x", "4"), strconv.Atoi)
+		ys, err := ToSliceE(xs)
+		assertEqual(t, ys, []int{1, 2})
+		assertEqual(t, errors.Is(err, strconv.ErrSyntax), true)
+	}
+	{
+		errOdd := errors.New("odd")
+		xs := WhereE(From(2, 4, 5, 6), func(x int) (bool, error) {
+			if !isEven(x) {
+				return false, errOdd
+			}
+			return x > 2, nil
+		})
+		ys, err := ToSliceE(xs)
+		assertEqual(t, ys, []int{4})
+		assertEqual(t, err, errOdd)
+	}
+	{
+		ys, err := ToSliceE(SelectE(Range(0, 3), func(x int) (int, error) { return x, nil }))
+		assertEqual(t, ys, []int{0, 1, 2})
+		assertEqual(t, err, nil)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	q := AsQuery(Range(0, 10))
+	assertEqual(t, q.Where(isEven).Skip(1).Take(3).ToSlice(), []int{2, 4, 6})
+	assertEqual(t, AsQuery(Select(q.Where(isEven), square)).Take(2).Append(0).ToSlice(), []int{0, 4, 0})
+
+	// re-enumerable and a Seq for free functions
+	ys := q.TakeWhile(lt(3)).Concat(FromSlice([]int{1, 2}))
+	assertEqual(t, ys.ToSlice(), []int{0, 1, 2, 1, 2})
+	assertEqual(t, ToSlice(ys), []int{0, 1, 2, 1, 2})
+	assertEqual(t, ys.Distinct().ToSlice(), []int{0, 1, 2})
+	assertEqual(t, ys.Union(From(5, 1)).ToSlice(), []int{0, 1, 2, 5})
+	assertEqual(t, ys.Intersect(From(2, 1)).ToSlice(), []int{1, 2})
+	assertEqual(t, ys.Except(From(1)).ToSlice(), []int{0, 2})
+	assertEqual(t, ToSlice(Select(q.Chunk(4), Clone[int])), [][]int{{0, 1, 2, 3}, {4, 5, 6, 7}, {8, 9}})
+
+	zs := AsQuery(FromSlice([]int{3, 1, 2, 10, 12})).
+		OrderBy(func(a, b int) int { return a%10 - b%10 }).
+		ThenByDescending(func(a, b int) int { return a - b }).
+		Skip(1)
+	assertEqual(t, zs.ToSlice(), []int{1, 12, 2, 3})
+	x, ok := zs.Last()
+	assertEqual(t, x, 3)
+	assertEqual(t, ok, true)
+	assertEqual(t, q.Any(gt(8)), true)
+	assertEqual(t, q.All(lt(8)), false)
+
+	// the capabilities of the wrapped Seq, and only those
+	n, ok := TryGetNonEnumeratedCount(AsQuery(FromSlice([]int{1, 2, 3})))
+	assertEqual(t, n, 3)
+	assertEqual(t, ok, true)
+	_, ok = TryGetNonEnumeratedCount(AsQuery(From(1, 2, 3)))
+	assertEqual(t, ok, false)
+	as, bs := UnzipSeq(AsQuery(From(Cons[int, string]{1, "a"}, Cons[int, string]{2, "b"})))
+	assertEqual(t, ToSlice(as), []int{1, 2})
+	assertEqual(t, ToSlice(bs), []string{"a", "b"})
+	closed := 0
+	_ = Close(AsQuery(onClose(From(1), func() error { closed++; return nil })))
+	assertEqual(t, closed, 1)
+	fail := AsQuery(Fail[int](errors.New("boom")))
+	_, ok = fail.Next()
+	assertEqual(t, ok, false)
+	assertEqual(t, Err(fail).Error(), "boom")
+}
+
+func TestFusion(t *testing.T) {
+	{
+		xs := Where(Select(Where(Range(0, 10), isEven), square), func(x int) bool { return x > 10 })
+		_, fused := xs.(stage[int, int])
+		assertEqual(t, fused, true)
+		assertEqual(t, ToSlice(xs), []int{16, 36, 64})
+		assertEqual(t, ToSlice(xs), []int{16, 36, 64})
+		assertEqual(t, ToSlice(Skip(Select(xs, strconv.Itoa), 1)), []string{"36", "64"})
+	}
+	{
+		xs := From(1, 2, 3, 4)
+		ys := Select(Where(xs, isEven), double)
+		assertEqual(t, ToSlice(Take(ys, 1)), []int{4})
+		assertEqual(t, ToSlice(ys), []int{8})
+	}
+	{
+		closed := 0
+		xs := onClose(From(1, 2, 3), func() error { closed++; return nil })
+		ys := Where(Select(xs, double), isEven)
+		x, _ := First(ys)
+		assertEqual(t, x, 2)
+		assertEqual(t, closed, 1)
+	}
+	{
+		xs := Select(Concat(From(1, 2), Fail[int](errors.New("boom"))), double)
+		ys, err := ToSliceE(Where(xs, isEven))
+		assertEqual(t, ys, []int{2, 4})
+		assertEqual(t, err.Error(), "boom")
+	}
+}
+
+func TestFusionAllocs(t *testing.T) {
+	xs := make([]int, 1000)
+	for _, n := range []int{10, 1000} {
+		allocs := testing.AllocsPerRun(10, func() {
+			Iterate(Skip(Select(Where(FromSlice(xs[:n]), isEven), double), 1), func(int) {})
+		})
+		// the pipeline itself allocates, the elements don't
+		if allocs > 20 {
+			t.Errorf("%v allocs for %d elements", allocs, n)
+		}
+	}
+}
+
+func TestIndexed(t *testing.T) {
+	{
+		calls := 0
+		xs := Select(Range(0, 5), func(x int) int { calls++; return x * 10 })
+		_, indexed := xs.(Indexed[int])
+		assertEqual(t, indexed, true)
+		assertEqual(t, Count(xs), 5)
+		n, ok := TryGetNonEnumeratedCount(xs)
+		assertEqual(t, n, 5)
+		assertEqual(t, ok, true)
+		x, ok := ElementAt(xs, 3)
+		assertEqual(t, x, 30)
+		assertEqual(t, ok, true)
+		x, ok = Last(xs)
+		assertEqual(t, x, 40)
+		assertEqual(t, ok, true)
+		assertEqual(t, calls, 2)
+		assertEqual(t, ToSlice(Skip(xs, 2)), []int{20, 30, 40})
+		assertEqual(t, ToSlice(Reverse(xs)), []int{40, 30, 20, 10, 0})
+		assertEqual(t, ToSlice(Reverse(Skip(xs, 3))), []int{40, 30})
+	}
+	{
+		xs := FromSlice([]int{1, 2, 3})
+		assertEqual(t, ToSlice(Skip(xs, -1)), []int{1, 2, 3})
+		assertEqual(t, ToSlice(Skip(xs, 5)), []int(nil))
+		assertEqual(t, Count(Skip(xs, 5)), 0)
+		_, ok := Last(Skip(xs, 3))
+		assertEqual(t, ok, false)
+		_, ok = ElementAt(xs, 3)
+		assertEqual(t, ok, false)
+	}
+	{
+		xs := Where(Range(0, 10), isEven)
+		_, ok := TryGetNonEnumeratedCount(xs)
+		assertEqual(t, ok, false)
+		assertEqual(t, Count(xs), 5)
+		assertEqual(t, ToSlice(Reverse(xs)), []int{8, 6, 4, 2, 0})
+		assertEqual(t, ToSlice(Reverse(xs)), []int{8, 6, 4, 2, 0})
+	}
+	{
+		xs := Reverse(From(1, 2, 3))
+		assertEqual(t, ToSlice(xs), []int{3, 2, 1})
+		assertEqual(t, ToSlice(xs), []int(nil))
+		ys, err := ToSliceE(Reverse(Concat(From(1, 2), Fail[int](errors.New("boom")))))
+		assertEqual(t, ys, []int(nil))
+		assertEqual(t, err.Error(), "boom")
+	}
+}
+
+func TestIndexedAllocs(t *testing.T) {
+	xs := FromSlice(make([]int, 1000))
+	for _, n := range []int{10, 1000} {
+		allocs := testing.AllocsPerRun(10, func() {
+			_ = Count(Skip(xs, 1000-n))
+			_, _ = Last(Reverse(Select(Skip(xs, 1000-n), double)))
+		})
+		if allocs > 10 {
+			t.Errorf("%v allocs for %d elements", allocs, n)
+		}
+	}
+}
+
+func BenchmarkLoop(b *testing.B) {
+	xs := Range(0, 1000)
+	ys := ToSlice(xs)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		sum := 0
+		for _, x := range ys[1:] {
+			if isEven(x) {
+				sum += double(x)
+			}
+		}
+	}
+}
+
+func BenchmarkSelectWhere(b *testing.B) {
+	xs := FromSlice(ToSlice(Range(0, 1000)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		sum := 0
+		Iterate(Select(Where(xs, isEven), double), func(x int) { sum += x })
+	}
+}
+
+func BenchmarkSelectWithIndex(b *testing.B) {
+	xs := FromSlice(ToSlice(Range(0, 1000)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		sum := 0
+		Iterate(SelectWithIndex(xs, func(x, i int) int { return x + i }), func(x int) { sum += x })
+	}
+}
+
+func BenchmarkSkip(b *testing.B) {
+	xs := FromSlice(ToSlice(Range(0, 1000)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		sum := 0
+		Iterate(Skip(xs, 1), func(x int) { sum += x })
+	}
+}
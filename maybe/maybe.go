@@ -18,6 +18,14 @@ func Nothing[T any]() Maybe[T] {
 	}
 }
 
+// Of lifts the comma-ok idiom, e.g. Of(xs.Next())
+func Of[T any](x T, ok bool) Maybe[T] {
+	if ok {
+		return Just(x)
+	}
+	return Nothing[T]()
+}
+
 func Unit[T any](x T) Maybe[T] {
 	return Just[T](x)
 }
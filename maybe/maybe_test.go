@@ -61,3 +61,8 @@ func TestMaybe(t *testing.T) {
 		assertEqual(t, y, Nothing[int]())
 	}
 }
+
+func TestOf(t *testing.T) {
+	assertEqual(t, Of(1, true), Just(1))
+	assertEqual(t, Of(1, false), Nothing[int]())
+}
@@ -30,24 +30,41 @@ func Unit[T any](x T) Seq[T] {
 
 // Bind aka flatMap
 func Bind[A, R any](xs Seq[A], f func(A) Seq[R]) Seq[R] {
-	var y Seq[R]
-	return SeqOf[R](func() (z R, ok bool) {
-	begin:
-		if y != nil {
-			z, ok = y.Next()
-			if ok {
-				return
-			}
-			y = nil
-		}
-		for {
-			var x A
-			x, ok = xs.Next()
-			if !ok {
-				return
-			}
-			y = f(x)
-			goto begin
+	return lift(xs, func(xs Seq[A]) Seq[R] {
+		var y Seq[R]
+		var err error
+		return &wrapSeq[R]{
+			Seq: SeqOf[R](func() (z R, ok bool) {
+				if err != nil {
+					return
+				}
+			begin:
+				if y != nil {
+					z, ok = y.Next()
+					if ok {
+						return
+					}
+					// stop at the first error of inner sequences
+					if err = Err(y); err != nil {
+						_ = Close(xs)
+						return
+					}
+					_ = Close(y)
+					y = nil
+				}
+				for {
+					var x A
+					x, ok = xs.Next()
+					if !ok {
+						return
+					}
+					y = enumerator(f(x))
+					goto begin
+				}
+			}),
+			// xs is closed and reported by lift
+			close: func() error { return Close(y) },
+			err:   func() error { return err },
 		}
 	})
 }
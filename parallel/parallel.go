@@ -0,0 +1,332 @@
+// Package parallel runs Select / Where / SelectMany and aggregates of a linq.Seq
+// on a bounded pool of goroutines, aka PLINQ.
+//
+//	xs := parallel.AsParallel(src, parallel.WithDegreeOfParallelism(4), parallel.AsOrdered())
+//	ys := parallel.AsSequential(parallel.Select(parallel.Where(xs, p), f))
+//
+// The stages are fused, a worker runs the whole pipeline on one source element,
+// the source itself is read by a single goroutine.
+package parallel
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/goghcrow/go-linq-object"
+)
+
+// ↓↓↓↓↓↓ Options ↓↓↓↓↓↓
+
+type Option func(*options)
+
+type options struct {
+	dop     int
+	ordered bool
+}
+
+// WithDegreeOfParallelism bounds the number of workers, GOMAXPROCS by default
+func WithDegreeOfParallelism(n int) Option {
+	if n < 1 {
+		panic("degree of parallelism must be positive")
+	}
+	return func(o *options) { o.dop = n }
+}
+
+// AsOrdered keeps the source order, results finished out of order wait in a reorder buffer,
+// which is bounded by bufferPerWorker * dop
+func AsOrdered() Option {
+	return func(o *options) { o.ordered = true }
+}
+
+const bufferPerWorker = 4
+
+// ↓↓↓↓↓↓ Parallel Sequence ↓↓↓↓↓↓
+
+// task runs the fused stages on one source element
+type task[T any] func(emit func(T))
+
+// Seq is a parallel query, nothing runs until AsSequential or an aggregate
+type Seq[T any] struct {
+	opts  options
+	tasks linq.Seq[task[T]]
+}
+
+func AsParallel[T any](xs linq.Seq[T], opts ...Option) *Seq[T] {
+	o := options{dop: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Seq[T]{
+		opts: o,
+		tasks: linq.Select(xs, func(x T) task[T] {
+			return func(emit func(T)) { emit(x) }
+		}),
+	}
+}
+
+// AsSequential runs the query and returns to linq.Seq,
+// Close stops the workers, Err reports the error of the source,
+// a panic in a worker is re-raised on Next
+func AsSequential[T any](p *Seq[T]) linq.Seq[T] {
+	return linq.Defer(func() linq.Seq[T] {
+		s := &seq[T]{}
+		s.Seq = linq.FromIter(func(yield func(T) bool) {
+			s.err = run(p, yield)
+		})
+		return s
+	})
+}
+
+type seq[T any] struct {
+	linq.Seq[T]
+	err error
+}
+
+func (s *seq[T]) Close() error { return linq.Close(s.Seq) }
+func (s *seq[T]) Err() error   { return s.err }
+
+// ↓↓↓↓↓↓ Operators ↓↓↓↓↓↓
+
+func Select[A, R any](p *Seq[A], f linq.Selector[A, R]) *Seq[R] {
+	return &Seq[R]{
+		opts: p.opts,
+		tasks: linq.Select(p.tasks, func(t task[A]) task[R] {
+			return func(emit func(R)) {
+				t(func(x A) { emit(f(x)) })
+			}
+		}),
+	}
+}
+
+func Where[A any](p *Seq[A], f linq.Pred[A]) *Seq[A] {
+	return &Seq[A]{
+		opts: p.opts,
+		tasks: linq.Select(p.tasks, func(t task[A]) task[A] {
+			return func(emit func(A)) {
+				t(func(x A) {
+					if f(x) {
+						emit(x)
+					}
+				})
+			}
+		}),
+	}
+}
+
+// SelectMany iterates f(x) in the worker, AsOrdered keeps the order inside f(x) as well
+func SelectMany[A, R any](p *Seq[A], f func(A) linq.Seq[R]) *Seq[R] {
+	return &Seq[R]{
+		opts: p.opts,
+		tasks: linq.Select(p.tasks, func(t task[A]) task[R] {
+			return func(emit func(R)) {
+				t(func(x A) {
+					for y := range linq.Values(f(x)) {
+						emit(y)
+					}
+				})
+			}
+		}),
+	}
+}
+
+// ↓↓↓↓↓↓ Aggregates ↓↓↓↓↓↓
+
+// Aggregate folds a partial result per worker starting from seed(),
+// and combines the partial results with combine, the elements reach
+// the workers in any order, so f and combine must not depend on it
+func Aggregate[T, A, R any](
+	p *Seq[T],
+	seed func() A,
+	f func(acc A, cur T) A,
+	combine func(A, A) A,
+	result linq.Selector[A, R],
+) (r R, err error) {
+	accs := make([]A, p.opts.dop)
+	for i := range accs {
+		accs[i] = seed()
+	}
+	pl := start(p, 0, func(w int, x T) { accs[w] = f(accs[w], x) })
+	if err = pl.wait(); err != nil {
+		return
+	}
+	acc := accs[0]
+	for _, a := range accs[1:] {
+		acc = combine(acc, a)
+	}
+	return result(acc), nil
+}
+
+func Sum[N linq.Number](p *Seq[N]) (N, error) {
+	add := func(a, b N) N { return a + b }
+	return Aggregate(p, func() (n N) { return }, add, add, linq.Id[N])
+}
+
+func Count[T any](p *Seq[T]) (int, error) {
+	return Aggregate(p,
+		func() int { return 0 },
+		func(n int, _ T) int { return n + 1 },
+		func(a, b int) int { return a + b },
+		linq.Id[int],
+	)
+}
+
+// ↓↓↓↓↓↓ Pool ↓↓↓↓↓↓
+
+// run yields the results of p on the calling goroutine
+func run[T any](p *Seq[T], yield func(T) bool) error {
+	pl := start(p, bufferPerWorker*p.opts.dop, nil)
+	emit := func(ys []T) bool {
+		<-pl.tokens
+		for _, y := range ys {
+			if !yield(y) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// reorder buffer
+	buf, next := map[int][]T{}, 0
+
+loop:
+	for r := range pl.results {
+		if !p.opts.ordered {
+			if !emit(r.ys) {
+				break
+			}
+			continue
+		}
+		buf[r.i] = r.ys
+		for ys, ok := buf[next]; ok; ys, ok = buf[next] {
+			delete(buf, next)
+			next++
+			if !emit(ys) {
+				break loop
+			}
+		}
+	}
+	pl.stop()
+	return pl.wait()
+}
+
+type (
+	job[T any] struct {
+		i int
+		t task[T]
+	}
+	result[T any] struct {
+		i  int
+		ys []T
+	}
+)
+
+type pool[T any] struct {
+	results chan result[T]
+	tokens  chan struct{} // jobs in flight, released by the consumer
+	done    chan struct{}
+	stop    func()
+
+	mu       sync.Mutex
+	err      error // of the source
+	panicked any
+}
+
+// start feeds the tasks of p to dop workers, a worker sends the results of one
+// source element to results, or hands them over to fold if not nil
+func start[T any](p *Seq[T], inFlight int, fold func(w int, x T)) *pool[T] {
+	pl := &pool[T]{
+		results: make(chan result[T], p.opts.dop),
+		done:    make(chan struct{}),
+	}
+	pl.stop = sync.OnceFunc(func() { close(pl.done) })
+	if inFlight > 0 {
+		pl.tokens = make(chan struct{}, inFlight)
+	}
+
+	var wg sync.WaitGroup
+	jobs := make(chan job[T])
+
+	// feeder, the only reader of the source
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		defer pl.recover()
+
+		src := p.tasks
+		if e, ok := src.(linq.Enumerable[task[T]]); ok {
+			src = e.Enumerator()
+		}
+		defer func() { _ = linq.Close(src) }()
+
+		for i := 0; ; i++ {
+			if pl.tokens != nil {
+				select {
+				case pl.tokens <- struct{}{}:
+				case <-pl.done:
+					return
+				}
+			}
+			t, ok := src.Next()
+			if !ok {
+				pl.mu.Lock()
+				pl.err = linq.Err(src)
+				pl.mu.Unlock()
+				return
+			}
+			select {
+			case jobs <- job[T]{i, t}:
+			case <-pl.done:
+				return
+			}
+		}
+	}()
+
+	for w := 0; w < p.opts.dop; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer pl.recover()
+			for j := range jobs {
+				if fold != nil {
+					j.t(func(x T) { fold(w, x) })
+					continue
+				}
+				var ys []T
+				j.t(func(y T) { ys = append(ys, y) })
+				select {
+				case pl.results <- result[T]{j.i, ys}:
+				case <-pl.done:
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(pl.results)
+	}()
+	return pl
+}
+
+func (pl *pool[T]) recover() {
+	if v := recover(); v != nil {
+		pl.mu.Lock()
+		if pl.panicked == nil {
+			pl.panicked = v
+		}
+		pl.mu.Unlock()
+		pl.stop()
+	}
+}
+
+// wait waits for all goroutines, and re-raises the first panic
+func (pl *pool[T]) wait() error {
+	for range pl.results {
+	}
+	if pl.panicked != nil {
+		panic(pl.panicked)
+	}
+	return pl.err
+}
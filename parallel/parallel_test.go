@@ -0,0 +1,133 @@
+package parallel
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goghcrow/go-linq-object"
+	"github.com/goghcrow/go-linq-object/yield/leaktest"
+)
+
+func assertEqual(t *testing.T, x, y any) {
+	if !reflect.DeepEqual(x, y) {
+		t.Fail()
+	}
+}
+
+func square(x int) int  { return x * x }
+func isEven(x int) bool { return x%2 == 0 }
+
+func TestOrdered(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	xs := AsParallel(linq.Range(0, 1000), WithDegreeOfParallelism(8), AsOrdered())
+	ys := AsSequential(Select(Where(xs, isEven), square))
+	want := linq.ToSlice(linq.Select(linq.Where(linq.Range(0, 1000), isEven), square))
+	assertEqual(t, linq.ToSlice(ys), want)
+	// re-enumerable source
+	assertEqual(t, linq.ToSlice(ys), want)
+
+	slow := Select(AsParallel(linq.Range(0, 20), WithDegreeOfParallelism(4), AsOrdered()), func(x int) int {
+		time.Sleep(time.Duration(20-x) * time.Millisecond)
+		return x
+	})
+	assertEqual(t, linq.ToSlice(AsSequential(slow)), linq.ToSlice(linq.Range(0, 20)))
+
+	zs := SelectMany(xs, func(x int) linq.Seq[int] { return linq.Repeat(x, 2) })
+	assertEqual(t, linq.ToSlice(linq.Take(AsSequential(zs), 4)), []int{0, 0, 1, 1})
+}
+
+func TestUnordered(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	xs := AsParallel(linq.Range(0, 1000), WithDegreeOfParallelism(8))
+	ys := linq.ToSlice(AsSequential(Select(Where(xs, isEven), square)))
+	slices.Sort(ys)
+	assertEqual(t, ys, linq.ToSlice(linq.Select(linq.Where(linq.Range(0, 1000), isEven), square)))
+}
+
+func TestDegreeOfParallelism(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	var running, peak atomic.Int32
+	xs := Select(AsParallel(linq.Range(0, 50), WithDegreeOfParallelism(3)), func(x int) int {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		return x
+	})
+	n, err := Count(xs)
+	assertEqual(t, n, 50)
+	assertEqual(t, err, nil)
+	assertEqual(t, peak.Load() <= 3, true)
+}
+
+func TestAbandon(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	xs := AsSequential(Select(AsParallel(linq.Infinite(1), AsOrdered()), square))
+	assertEqual(t, linq.ToSlice(linq.Take(xs, 3)), []int{1, 1, 1})
+
+	ys := AsSequential(AsParallel(linq.Infinite(1)))
+	for range linq.Values(ys) {
+		break
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	xs := AsParallel(linq.Range(1, 101), WithDegreeOfParallelism(4))
+	sum, err := Sum(xs)
+	assertEqual(t, sum, 5050)
+	assertEqual(t, err, nil)
+
+	m, _ := Aggregate(xs,
+		func() int { return 0 },
+		func(acc, x int) int { return max(acc, x) },
+		func(a, b int) int { return max(a, b) },
+		square,
+	)
+	assertEqual(t, m, 10000)
+}
+
+func TestErr(t *testing.T) {
+	boom := errors.New("boom")
+	src := func() linq.Seq[int] {
+		i := 0
+		return linq.SeqOfE(func() (int, bool, error) {
+			if i++; i > 3 {
+				return 0, false, boom
+			}
+			return i, true, nil
+		})
+	}
+	_, err := Sum(AsParallel(src()))
+	assertEqual(t, err, boom)
+
+	ys, err := linq.ToSliceE(AsSequential(AsParallel(src(), AsOrdered())))
+	assertEqual(t, ys, []int{1, 2, 3})
+	assertEqual(t, err, boom)
+}
+
+func TestPanic(t *testing.T) {
+	defer leaktest.Check(t)()
+	defer func() { assertEqual(t, recover(), "boom") }()
+
+	xs := Select(AsParallel(linq.Range(0, 100)), func(x int) int {
+		if x == 42 {
+			panic("boom")
+		}
+		return x
+	})
+	linq.ToSlice(AsSequential(xs))
+	t.Fail()
+}
@@ -0,0 +1,122 @@
+package linq
+
+import (
+	"iter"
+
+	"github.com/goghcrow/go-linq-object/maybe"
+)
+
+// ↓↓↓↓↓↓ Fluent Query ↓↓↓↓↓↓
+// Methods can't have type parameters, so Query only chains the operators that keep T,
+// the type-changing ones stay free functions, wrap their result with AsQuery again, e.g.
+//
+//	AsQuery(Select(AsQuery(xs).Where(p).Skip(1), f)).Take(2).ToSlice()
+
+// Query is itself a Seq, the operators see through it, so Close, Err,
+// re-enumerability and O(1) access of the wrapped one are kept
+type Query[T any] struct {
+	Seq[T]
+}
+
+func AsQuery[T any](xs Seq[T]) Query[T] {
+	if q, ok := xs.(Query[T]); ok {
+		return q
+	}
+	return Query[T]{xs}
+}
+
+func (q Query[T]) unwrap() Seq[T] { return q.Seq }
+
+// ↓↓↓↓↓↓ Operators ↓↓↓↓↓↓
+
+func (q Query[T]) Where(p Pred[T]) Query[T]             { return AsQuery(Where(q.Seq, p)) }
+func (q Query[T]) WhereWithIndex(p IdxPred[T]) Query[T] { return AsQuery(WhereWithIndex(q.Seq, p)) }
+func (q Query[T]) Take(cnt int) Query[T]                { return AsQuery(Take(q.Seq, cnt)) }
+func (q Query[T]) TakeWhile(p Pred[T]) Query[T]         { return AsQuery(TakeWhile(q.Seq, p)) }
+func (q Query[T]) TakeWhileWithIndex(p IdxPred[T]) Query[T] {
+	return AsQuery(TakeWhileWithIndex(q.Seq, p))
+}
+func (q Query[T]) Skip(cnt int) Query[T]        { return AsQuery(Skip(q.Seq, cnt)) }
+func (q Query[T]) SkipWhile(p Pred[T]) Query[T] { return AsQuery(SkipWhile(q.Seq, p)) }
+func (q Query[T]) SkipWhileWithIndex(p IdxPred[T]) Query[T] {
+	return AsQuery(SkipWhileWithIndex(q.Seq, p))
+}
+func (q Query[T]) Append(x T) Query[T]       { return AsQuery(Append(q.Seq, x)) }
+func (q Query[T]) Prepend(x T) Query[T]      { return AsQuery(Prepend(q.Seq, x)) }
+func (q Query[T]) Concat(ys Seq[T]) Query[T] { return AsQuery(Concat(q.Seq, ys)) }
+func (q Query[T]) Cycle() Query[T]           { return AsQuery(Cycle(q.Seq)) }
+
+// Distinct / Union / Intersect / Except compare the elements as map keys of type any,
+// so they panic on an element that is not comparable at runtime, as map[any] does
+
+func (q Query[T]) Distinct() Query[T]       { return AsQuery(DistinctBy(q.Seq, anyKey[T])) }
+func (q Query[T]) Union(ys Seq[T]) Query[T] { return AsQuery(UnionBy(q.Seq, ys, anyKey[T])) }
+func (q Query[T]) Intersect(ys Seq[T]) Query[T] {
+	return AsQuery(IntersectBy(q.Seq, Select(ys, anyKey[T]), anyKey[T]))
+}
+func (q Query[T]) Except(ys Seq[T]) Query[T] {
+	return AsQuery(ExceptBy(q.Seq, Select(ys, anyKey[T]), anyKey[T]))
+}
+
+func anyKey[T any](x T) any { return x }
+
+func (q Query[T]) SelectE(f func(T) (T, error)) Query[T]   { return AsQuery(SelectE(q.Seq, f)) }
+func (q Query[T]) WhereE(p func(T) (bool, error)) Query[T] { return AsQuery(WhereE(q.Seq, p)) }
+
+// Chunk, Window and Pairwise return a plain Seq,
+// since Query[T] returning Query[[]T] is an instantiation cycle
+
+func (q Query[T]) Chunk(n int) Seq[[]T]           { return Chunk(q.Seq, n) }
+func (q Query[T]) Window(size, step int) Seq[[]T] { return Window(q.Seq, size, step) }
+func (q Query[T]) Pairwise() Seq[Cons[T, T]]      { return Pairwise(q.Seq) }
+
+// ↓↓↓↓↓↓ Terminals ↓↓↓↓↓↓
+
+func (q Query[T]) ToSlice() []T                        { return ToSlice(q.Seq) }
+func (q Query[T]) ToSliceE() ([]T, error)              { return ToSliceE(q.Seq) }
+func (q Query[T]) Iterate(f func(T))                   { Iterate(q.Seq, f) }
+func (q Query[T]) IterateWithIndex(f func(T, Index))   { IterateWithIndex(q.Seq, f) }
+func (q Query[T]) First() (T, bool)                    { return First(q.Seq) }
+func (q Query[T]) FirstWhile(p Pred[T]) (T, bool)      { return FirstWhile(q.Seq, p) }
+func (q Query[T]) FirstOrDefault(def T) T              { return FirstOrDefault(q.Seq, def) }
+func (q Query[T]) FirstMaybe() maybe.Maybe[T]          { return FirstMaybe(q.Seq) }
+func (q Query[T]) Last() (T, bool)                     { return Last(q.Seq) }
+func (q Query[T]) LastWhile(p Pred[T]) (T, bool)       { return LastWhile(q.Seq, p) }
+func (q Query[T]) LastOrDefault(def T) T               { return LastOrDefault(q.Seq, def) }
+func (q Query[T]) LastMaybe() maybe.Maybe[T]           { return LastMaybe(q.Seq) }
+func (q Query[T]) ElementAt(i Index) (T, bool)         { return ElementAt(q.Seq, i) }
+func (q Query[T]) ElementAtOrDefault(i Index, def T) T { return ElementAtOrDefault(q.Seq, i, def) }
+func (q Query[T]) Single() (T, error)                  { return Single(q.Seq) }
+func (q Query[T]) SingleWhile(p Pred[T]) (T, error)    { return SingleWhile(q.Seq, p) }
+func (q Query[T]) All(p Pred[T]) bool                  { return All(q.Seq, p) }
+func (q Query[T]) Any(p Pred[T]) bool                  { return Any(q.Seq, p) }
+func (q Query[T]) AnyElem() bool                       { return AnyElem(q.Seq) }
+func (q Query[T]) Reduce(f func(acc, cur T) T) (T, bool) {
+	return Reduce(q.Seq, f)
+}
+
+func (q Query[T]) Values() iter.Seq[T]            { return Values(q.Seq) }
+func (q Query[T]) Enumerate() iter.Seq2[Index, T] { return Enumerate(q.Seq) }
+
+// ↓↓↓↓↓↓ Ordered Query ↓↓↓↓↓↓
+
+// OrderedQuery is a Query over OrderedSeq, which can be ordered further
+type OrderedQuery[T any] struct {
+	Query[T]
+}
+
+func (q Query[T]) OrderBy(cmp func(a, b T) int) OrderedQuery[T] {
+	return OrderedQuery[T]{AsQuery[T](OrderByFunc(q.Seq, cmp))}
+}
+
+func (q Query[T]) OrderByDescending(cmp func(a, b T) int) OrderedQuery[T] {
+	return OrderedQuery[T]{AsQuery[T](OrderByDescendingFunc(q.Seq, cmp))}
+}
+
+func (q OrderedQuery[T]) ThenBy(cmp func(a, b T) int) OrderedQuery[T] {
+	return OrderedQuery[T]{AsQuery[T](ThenByFunc(q.Seq.(OrderedSeq[T]), cmp))}
+}
+
+func (q OrderedQuery[T]) ThenByDescending(cmp func(a, b T) int) OrderedQuery[T] {
+	return OrderedQuery[T]{AsQuery[T](ThenByDescendingFunc(q.Seq.(OrderedSeq[T]), cmp))}
+}
@@ -0,0 +1,217 @@
+package queryable
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/goghcrow/go-linq-object"
+)
+
+// ↓↓↓↓↓↓ Expression Tree ↓↓↓↓↓↓
+// A small typed expression language over the element of a query,
+// the constructors check the types in Go, the providers check the fields
+// against the element type, and execute or translate the tree.
+
+// Node is the untyped view of an expression for the providers
+type Node interface {
+	// Type of the value
+	Type() reflect.Type
+	String() string
+}
+
+// Expr is a Node of the value type V
+type Expr[V any] struct {
+	Node
+}
+
+type (
+	// FieldExpr accesses a field of the element, or of a side in the result of Join
+	FieldExpr struct {
+		Name string
+		T    reflect.Type
+		Of   Side
+	}
+	// ElemExpr is the element itself
+	ElemExpr struct {
+		T reflect.Type
+	}
+	ConstExpr struct {
+		Value any
+		T     reflect.Type
+	}
+	UnaryExpr struct {
+		Op UnaryOp
+		X  Node
+	}
+	BinaryExpr struct {
+		Op   BinaryOp
+		L, R Node
+		T    reflect.Type
+	}
+	// NewExpr builds a struct of type T, aka projection
+	NewExpr struct {
+		T      reflect.Type
+		Fields []Assign
+	}
+	Assign struct {
+		Name string
+		X    Node
+	}
+	// KeyExpr is the key of the group in a query after GroupBy
+	KeyExpr struct {
+		T reflect.Type
+	}
+	// AggExpr aggregates the elements of the group in a query after GroupBy,
+	// X is evaluated on the elements, nil for Count
+	AggExpr struct {
+		Fn AggFunc
+		X  Node
+		T  reflect.Type
+	}
+)
+
+type Side int
+
+const (
+	SideElem Side = iota
+	SideOuter
+	SideInner
+)
+
+type UnaryOp int
+
+const (
+	OpNot UnaryOp = iota
+	OpNeg
+)
+
+type BinaryOp int
+
+const (
+	OpEq BinaryOp = iota
+	OpNe
+	OpLt
+	OpLe
+	OpGt
+	OpGe
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpMod
+	OpAnd
+	OpOr
+)
+
+type AggFunc int
+
+const (
+	AggCount AggFunc = iota
+	AggSum
+	AggMin
+	AggMax
+	AggAvg
+)
+
+func (op UnaryOp) String() string { return [...]string{"!", "-"}[op] }
+func (op BinaryOp) String() string {
+	return [...]string{"==", "!=", "<", "<=", ">", ">=", "+", "-", "*", "/", "%", "&&", "||"}[op]
+}
+func (fn AggFunc) String() string { return [...]string{"Count", "Sum", "Min", "Max", "Avg"}[fn] }
+
+// IsComparison reports whether op yields bool from two operands of the same type
+func (op BinaryOp) IsComparison() bool { return op <= OpGe }
+
+func (e *FieldExpr) Type() reflect.Type  { return e.T }
+func (e *ElemExpr) Type() reflect.Type   { return e.T }
+func (e *ConstExpr) Type() reflect.Type  { return e.T }
+func (e *UnaryExpr) Type() reflect.Type  { return e.X.Type() }
+func (e *BinaryExpr) Type() reflect.Type { return e.T }
+func (e *NewExpr) Type() reflect.Type    { return e.T }
+func (e *KeyExpr) Type() reflect.Type    { return e.T }
+func (e *AggExpr) Type() reflect.Type    { return e.T }
+
+func (e *FieldExpr) String() string {
+	return [...]string{"", "outer.", "inner."}[e.Of] + e.Name
+}
+func (e *ElemExpr) String() string  { return "it" }
+func (e *ConstExpr) String() string { return fmt.Sprintf("%#v", e.Value) }
+func (e *UnaryExpr) String() string { return e.Op.String() + e.X.String() }
+func (e *BinaryExpr) String() string {
+	return "(" + e.L.String() + " " + e.Op.String() + " " + e.R.String() + ")"
+}
+func (e *KeyExpr) String() string { return "Key" }
+
+func (e *NewExpr) String() string {
+	fs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		fs[i] = f.Name + ": " + f.X.String()
+	}
+	return e.T.String() + "{" + strings.Join(fs, ", ") + "}"
+}
+
+func (e *AggExpr) String() string {
+	if e.X == nil {
+		return e.Fn.String() + "()"
+	}
+	return e.Fn.String() + "(" + e.X.String() + ")"
+}
+
+// ↓↓↓↓↓↓ Constructors ↓↓↓↓↓↓
+
+func typeOf[V any]() reflect.Type { return reflect.TypeFor[V]() }
+
+func Field[V any](name string) Expr[V] { return Expr[V]{&FieldExpr{name, typeOf[V](), SideElem}} }
+func Elem[V any]() Expr[V]             { return Expr[V]{&ElemExpr{typeOf[V]()}} }
+func Const[V any](v V) Expr[V]         { return Expr[V]{&ConstExpr{v, typeOf[V]()}} }
+
+func compare[V any](op BinaryOp, l, r Expr[V]) Expr[bool] {
+	return Expr[bool]{&BinaryExpr{op, l.Node, r.Node, typeOf[bool]()}}
+}
+
+func arith[V any](op BinaryOp, l, r Expr[V]) Expr[V] {
+	return Expr[V]{&BinaryExpr{op, l.Node, r.Node, typeOf[V]()}}
+}
+
+func Eq[V comparable](l, r Expr[V]) Expr[bool]   { return compare(OpEq, l, r) }
+func Ne[V comparable](l, r Expr[V]) Expr[bool]   { return compare(OpNe, l, r) }
+func Lt[V linq.Ordered](l, r Expr[V]) Expr[bool] { return compare(OpLt, l, r) }
+func Le[V linq.Ordered](l, r Expr[V]) Expr[bool] { return compare(OpLe, l, r) }
+func Gt[V linq.Ordered](l, r Expr[V]) Expr[bool] { return compare(OpGt, l, r) }
+func Ge[V linq.Ordered](l, r Expr[V]) Expr[bool] { return compare(OpGe, l, r) }
+
+func Add[V linq.Number](l, r Expr[V]) Expr[V]  { return arith(OpAdd, l, r) }
+func Sub[V linq.Number](l, r Expr[V]) Expr[V]  { return arith(OpSub, l, r) }
+func Mul[V linq.Number](l, r Expr[V]) Expr[V]  { return arith(OpMul, l, r) }
+func Div[V linq.Number](l, r Expr[V]) Expr[V]  { return arith(OpDiv, l, r) }
+func Mod[V linq.Integer](l, r Expr[V]) Expr[V] { return arith(OpMod, l, r) }
+
+func And(l, r Expr[bool]) Expr[bool] { return arith(OpAnd, l, r) }
+func Or(l, r Expr[bool]) Expr[bool]  { return arith(OpOr, l, r) }
+func Not(x Expr[bool]) Expr[bool]    { return Expr[bool]{&UnaryExpr{OpNot, x.Node}} }
+
+func Neg[V linq.Signed | linq.Float](x Expr[V]) Expr[V] { return Expr[V]{&UnaryExpr{OpNeg, x.Node}} }
+
+// OuterField and InnerField access the sides in the result of Join
+
+func OuterField[V any](name string) Expr[V] { return Expr[V]{&FieldExpr{name, typeOf[V](), SideOuter}} }
+func InnerField[V any](name string) Expr[V] { return Expr[V]{&FieldExpr{name, typeOf[V](), SideInner}} }
+
+// New projects into the fields of struct R, e.g. New[Row](Set("Name", Field[string]("Name")))
+func New[R any](fields ...Assign) Expr[R] { return Expr[R]{&NewExpr{typeOf[R](), fields}} }
+
+func Set[V any](name string, x Expr[V]) Assign { return Assign{name, x.Node} }
+
+// ↓↓↓↓↓↓ Group ↓↓↓↓↓↓
+
+func Key[K any]() Expr[K] { return Expr[K]{&KeyExpr{typeOf[K]()}} }
+
+func Count() Expr[int] { return Expr[int]{&AggExpr{AggCount, nil, typeOf[int]()}} }
+
+func Sum[V linq.Number](x Expr[V]) Expr[V]  { return Expr[V]{&AggExpr{AggSum, x.Node, typeOf[V]()}} }
+func Min[V linq.Ordered](x Expr[V]) Expr[V] { return Expr[V]{&AggExpr{AggMin, x.Node, typeOf[V]()}} }
+func Max[V linq.Ordered](x Expr[V]) Expr[V] { return Expr[V]{&AggExpr{AggMax, x.Node, typeOf[V]()}} }
+func Avg[V linq.Number](x Expr[V]) Expr[float64] {
+	return Expr[float64]{&AggExpr{AggAvg, x.Node, typeOf[float64]()}}
+}
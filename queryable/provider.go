@@ -0,0 +1,385 @@
+package queryable
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+
+	"github.com/goghcrow/go-linq-object"
+)
+
+// ↓↓↓↓↓↓ Seq Provider ↓↓↓↓↓↓
+
+// SeqProvider compiles the tree down to the linq operators over an in-memory source,
+// the expressions are checked against the element types and evaluated by reflection
+type SeqProvider struct{}
+
+func (SeqProvider) Execute(q Query) (linq.Seq[any], error) {
+	xs, err := compile(q)
+	if err != nil {
+		return nil, err
+	}
+	return linq.Select(xs, func(x any) any {
+		if g, ok := x.(*group); ok {
+			return g.query.Group(g.key, g.elems)
+		}
+		return x
+	}), nil
+}
+
+// group is a group of GroupByQuery inside the compiled operators
+type group struct {
+	query *GroupByQuery
+	key   any
+	elems []any
+}
+
+// joined is the element the Result of a JoinQuery is evaluated on
+type joined struct {
+	outer, inner any
+}
+
+func compile(q Query) (linq.Seq[any], error) {
+	if src, ok := q.(*SourceQuery); ok {
+		if src.Seq == nil {
+			return nil, fmt.Errorf("queryable: %s is not an in-memory source", src)
+		}
+		return src.Seq, nil
+	}
+	if j, ok := q.(*JoinQuery); ok {
+		return compileJoin(j)
+	}
+
+	var srcQuery Query
+	switch q := q.(type) {
+	case *WhereQuery:
+		srcQuery = q.Src
+	case *SelectQuery:
+		srcQuery = q.Src
+	case *OrderByQuery:
+		srcQuery = q.Src
+	case *TakeQuery:
+		srcQuery = q.Src
+	case *SkipQuery:
+		srcQuery = q.Src
+	case *GroupByQuery:
+		srcQuery = q.Src
+	default:
+		return nil, fmt.Errorf("queryable: unsupported query %T", q)
+	}
+	src, err := compile(srcQuery)
+	if err != nil {
+		return nil, err
+	}
+	s := ScopeOf(srcQuery)
+
+	switch q := q.(type) {
+	case *WhereQuery:
+		pred, err := compileExpr(q.Pred, s)
+		if err != nil {
+			return nil, err
+		}
+		return linq.Where(src, func(x any) bool { return pred(x).(bool) }), nil
+	case *SelectQuery:
+		proj, err := compileExpr(q.Proj, s)
+		if err != nil {
+			return nil, err
+		}
+		return linq.Select(src, proj), nil
+	case *OrderByQuery:
+		keys := make([]func(any) any, len(q.Keys))
+		for i, k := range q.Keys {
+			if keys[i], err = compileExpr(k.Key, s); err != nil {
+				return nil, err
+			}
+		}
+		return linq.OrderByFunc(src, func(a, b any) int {
+			for i, key := range keys {
+				c := compareValues(key(a), key(b))
+				if q.Keys[i].Desc {
+					c = -c
+				}
+				if c != 0 {
+					return c
+				}
+			}
+			return 0
+		}), nil
+	case *TakeQuery:
+		return linq.Take(src, q.N), nil
+	case *SkipQuery:
+		return linq.Skip(src, q.N), nil
+	case *GroupByQuery:
+		key, err := compileExpr(q.Key, s)
+		if err != nil {
+			return nil, err
+		}
+		return linq.Select(linq.GroupBy(src, key), func(g linq.Grouping[any, any]) any {
+			return &group{q, g.Key, linq.ToSlice(g.Seq)}
+		}), nil
+	}
+	panic("unreachable")
+}
+
+func compileJoin(q *JoinQuery) (linq.Seq[any], error) {
+	outer, err := compile(q.Outer)
+	if err != nil {
+		return nil, err
+	}
+	inner, err := compile(q.Inner)
+	if err != nil {
+		return nil, err
+	}
+	outerKey, err := compileExpr(q.OuterKey, ScopeOf(q.Outer))
+	if err != nil {
+		return nil, err
+	}
+	innerKey, err := compileExpr(q.InnerKey, ScopeOf(q.Inner))
+	if err != nil {
+		return nil, err
+	}
+	result, err := compileExpr(q.Result, Scope{Elem: q.Outer.Elem(), Inner: q.Inner.Elem()})
+	if err != nil {
+		return nil, err
+	}
+	return linq.Join(outer, inner, outerKey, innerKey, func(o, i any) any {
+		return result(joined{o, i})
+	}), nil
+}
+
+// compileExpr checks n in scope s, and returns the evaluator on an element
+func compileExpr(n Node, s Scope) (func(any) any, error) {
+	switch n := n.(type) {
+	case *FieldExpr:
+		if s.Group != nil {
+			return nil, fmt.Errorf("queryable: field %s after GroupBy is not in an aggregate", n.Name)
+		}
+		elem, side := s.Elem, func(x any) any { return x }
+		switch {
+		case s.Inner != nil && n.Of == SideElem:
+			return nil, fmt.Errorf("queryable: field %s in the result of Join is neither OuterField nor InnerField", n)
+		case s.Inner == nil && n.Of != SideElem:
+			return nil, fmt.Errorf("queryable: %s outside the result of Join", n)
+		case n.Of == SideOuter:
+			side = func(x any) any { return x.(joined).outer }
+		case n.Of == SideInner:
+			elem, side = s.Inner, func(x any) any { return x.(joined).inner }
+		}
+		if elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("queryable: field %s of %s", n.Name, elem)
+		}
+		f, ok := elem.FieldByName(n.Name)
+		if !ok || !f.IsExported() {
+			return nil, fmt.Errorf("queryable: %s has no field %s", elem, n.Name)
+		}
+		if f.Type != n.T {
+			return nil, fmt.Errorf("queryable: field %s is %s, not %s", n.Name, f.Type, n.T)
+		}
+		return func(x any) any {
+			return reflect.Indirect(reflect.ValueOf(side(x))).FieldByIndex(f.Index).Interface()
+		}, nil
+
+	case *ElemExpr:
+		if s.Group != nil || s.Inner != nil || s.Elem != n.T {
+			return nil, fmt.Errorf("queryable: element is %s, not %s", s.Elem, n.T)
+		}
+		return func(x any) any { return x }, nil
+
+	case *ConstExpr:
+		return func(any) any { return n.Value }, nil
+
+	case *UnaryExpr:
+		x, err := compileExpr(n.X, s)
+		if err != nil {
+			return nil, err
+		}
+		if n.Op == OpNot {
+			return func(e any) any { return !x(e).(bool) }, nil
+		}
+		zero := reflect.Zero(n.Type()).Interface()
+		return func(e any) any { return arithmetic(OpSub, zero, x(e), n.Type()) }, nil
+
+	case *BinaryExpr:
+		if n.L.Type() != n.R.Type() {
+			return nil, fmt.Errorf("queryable: %s: mismatched types %s and %s", n, n.L.Type(), n.R.Type())
+		}
+		l, err := compileExpr(n.L, s)
+		if err != nil {
+			return nil, err
+		}
+		r, err := compileExpr(n.R, s)
+		if err != nil {
+			return nil, err
+		}
+		return binary(n, l, r), nil
+
+	case *NewExpr:
+		if n.T.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("queryable: New %s is not a struct", n.T)
+		}
+		idx := make([][]int, len(n.Fields))
+		xs := make([]func(any) any, len(n.Fields))
+		for i, a := range n.Fields {
+			f, ok := n.T.FieldByName(a.Name)
+			if !ok || !f.IsExported() {
+				return nil, fmt.Errorf("queryable: %s has no field %s", n.T, a.Name)
+			}
+			if !a.X.Type().AssignableTo(f.Type) {
+				return nil, fmt.Errorf("queryable: %s is not assignable to field %s of %s", a.X.Type(), a.Name, n.T)
+			}
+			var err error
+			if xs[i], err = compileExpr(a.X, s); err != nil {
+				return nil, err
+			}
+			idx[i] = f.Index
+		}
+		return func(e any) any {
+			v := reflect.New(n.T).Elem()
+			for i, x := range xs {
+				if x := x(e); x != nil {
+					v.FieldByIndex(idx[i]).Set(reflect.ValueOf(x))
+				}
+			}
+			return v.Interface()
+		}, nil
+
+	case *KeyExpr:
+		if s.Group == nil {
+			return nil, fmt.Errorf("queryable: Key without GroupBy")
+		}
+		if k := s.Group.Key.Type(); k != n.T {
+			return nil, fmt.Errorf("queryable: Key is %s, not %s", k, n.T)
+		}
+		return func(e any) any { return e.(*group).key }, nil
+
+	case *AggExpr:
+		if s.Group == nil {
+			return nil, fmt.Errorf("queryable: %s without GroupBy", n.Fn)
+		}
+		if n.Fn == AggCount {
+			return func(e any) any { return len(e.(*group).elems) }, nil
+		}
+		x, err := compileExpr(n.X, Scope{Elem: s.Elem})
+		if err != nil {
+			return nil, err
+		}
+		return aggregate(n, x), nil
+	}
+	return nil, fmt.Errorf("queryable: unsupported expression %T", n)
+}
+
+func binary(n *BinaryExpr, l, r func(any) any) func(any) any {
+	switch n.Op {
+	case OpAnd:
+		return func(e any) any { return l(e).(bool) && r(e).(bool) }
+	case OpOr:
+		return func(e any) any { return l(e).(bool) || r(e).(bool) }
+	case OpEq:
+		return func(e any) any { return l(e) == r(e) }
+	case OpNe:
+		return func(e any) any { return l(e) != r(e) }
+	case OpLt, OpLe, OpGt, OpGe:
+		return func(e any) any {
+			c := compareValues(l(e), r(e))
+			switch n.Op {
+			case OpLt:
+				return c < 0
+			case OpLe:
+				return c <= 0
+			case OpGt:
+				return c > 0
+			default:
+				return c >= 0
+			}
+		}
+	default:
+		return func(e any) any { return arithmetic(n.Op, l(e), r(e), n.T) }
+	}
+}
+
+func aggregate(n *AggExpr, x func(any) any) func(any) any {
+	return func(e any) any {
+		elems := e.(*group).elems
+		switch n.Fn {
+		case AggSum, AggAvg:
+			t := n.X.Type()
+			sum := reflect.Zero(t).Interface()
+			for _, el := range elems {
+				sum = arithmetic(OpAdd, sum, x(el), t)
+			}
+			if n.Fn == AggSum {
+				return sum
+			}
+			return reflect.ValueOf(sum).Convert(n.T).Float() / float64(len(elems))
+		default:
+			r := x(elems[0])
+			for _, el := range elems[1:] {
+				v := x(el)
+				if c := compareValues(v, r); c < 0 && n.Fn == AggMin || c > 0 && n.Fn == AggMax {
+					r = v
+				}
+			}
+			return r
+		}
+	}
+}
+
+// ↓↓↓↓↓↓ Reflection ↓↓↓↓↓↓
+
+func compareValues(a, b any) int {
+	x, y := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case x.CanInt():
+		return cmp.Compare(x.Int(), y.Int())
+	case x.CanUint():
+		return cmp.Compare(x.Uint(), y.Uint())
+	case x.CanFloat():
+		return cmp.Compare(x.Float(), y.Float())
+	case x.Kind() == reflect.String:
+		return cmp.Compare(x.String(), y.String())
+	}
+	panic(fmt.Sprintf("queryable: %s is not ordered", x.Type()))
+}
+
+func arithmetic(op BinaryOp, a, b any, t reflect.Type) any {
+	x, y, r := reflect.ValueOf(a), reflect.ValueOf(b), reflect.New(t).Elem()
+	switch {
+	case x.CanInt():
+		r.SetInt(intArith(op, x.Int(), y.Int()))
+	case x.CanUint():
+		r.SetUint(intArith(op, x.Uint(), y.Uint()))
+	case x.CanFloat():
+		i, j := x.Float(), y.Float()
+		switch op {
+		case OpAdd:
+			r.SetFloat(i + j)
+		case OpSub:
+			r.SetFloat(i - j)
+		case OpMul:
+			r.SetFloat(i * j)
+		default:
+			r.SetFloat(i / j)
+		}
+	default:
+		panic(fmt.Sprintf("queryable: %s is not a number", x.Type()))
+	}
+	return r.Interface()
+}
+
+func intArith[N linq.Integer](op BinaryOp, i, j N) N {
+	switch op {
+	case OpAdd:
+		return i + j
+	case OpSub:
+		return i - j
+	case OpMul:
+		return i * j
+	case OpDiv:
+		return i / j
+	default:
+		return i % j
+	}
+}
@@ -0,0 +1,254 @@
+// Package queryable is the expression tree half of LINQ, aka IQueryable.
+//
+// The operators build a tree of Query nodes over a typed expression language
+// instead of closures, a Provider executes or translates the tree,
+// SeqProvider, the default one, compiles it down to the linq operators.
+//
+//	q := queryable.FromSeq(linq.FromSlice(pets))
+//	q = queryable.Where(q, queryable.Gt(queryable.Field[int]("Age"), queryable.Const(3)))
+//	names, err := queryable.ToSlice(queryable.Select(q, queryable.Field[string]("Name")))
+package queryable
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/goghcrow/go-linq-object"
+)
+
+// ↓↓↓↓↓↓ Query Tree ↓↓↓↓↓↓
+
+// Query is a node of the query tree, Src is the input of an operator
+type Query interface {
+	// Elem is the type of the elements of the result
+	Elem() reflect.Type
+	String() string
+}
+
+type (
+	SourceQuery struct {
+		Name string // table name for the translating providers
+		T    reflect.Type
+		Seq  linq.Seq[any] // in-memory elements, nil for a table
+	}
+	WhereQuery struct {
+		Src  Query
+		Pred Node
+	}
+	SelectQuery struct {
+		Src  Query
+		Proj Node
+	}
+	OrderByQuery struct {
+		Src  Query
+		Keys []SortKey // ThenBy appends
+	}
+	SortKey struct {
+		Key  Node
+		Desc bool
+	}
+	TakeQuery struct {
+		Src Query
+		N   int
+	}
+	SkipQuery struct {
+		Src Query
+		N   int
+	}
+	// GroupByQuery yields linq.Grouping[K, E], the following Where / OrderBy / Select
+	// see the groups through Key and the aggregates
+	GroupByQuery struct {
+		Src Query
+		Key Node
+		T   reflect.Type
+		// Group builds the linq.Grouping of the result for the in-memory providers
+		Group func(key any, elems []any) any
+	}
+	// JoinQuery is an inner join, Result sees both sides through OuterField / InnerField
+	JoinQuery struct {
+		Outer, Inner       Query
+		OuterKey, InnerKey Node
+		Result             Node
+	}
+)
+
+func (q *SourceQuery) Elem() reflect.Type  { return q.T }
+func (q *WhereQuery) Elem() reflect.Type   { return q.Src.Elem() }
+func (q *SelectQuery) Elem() reflect.Type  { return q.Proj.Type() }
+func (q *OrderByQuery) Elem() reflect.Type { return q.Src.Elem() }
+func (q *TakeQuery) Elem() reflect.Type    { return q.Src.Elem() }
+func (q *SkipQuery) Elem() reflect.Type    { return q.Src.Elem() }
+func (q *GroupByQuery) Elem() reflect.Type { return q.T }
+func (q *JoinQuery) Elem() reflect.Type    { return q.Result.Type() }
+
+func (q *SourceQuery) String() string {
+	if q.Name != "" {
+		return q.Name
+	}
+	return "Seq[" + q.T.String() + "]"
+}
+func (q *WhereQuery) String() string  { return q.Src.String() + ".Where(" + q.Pred.String() + ")" }
+func (q *SelectQuery) String() string { return q.Src.String() + ".Select(" + q.Proj.String() + ")" }
+func (q *TakeQuery) String() string   { return fmt.Sprintf("%s.Take(%d)", q.Src, q.N) }
+func (q *SkipQuery) String() string   { return fmt.Sprintf("%s.Skip(%d)", q.Src, q.N) }
+func (q *GroupByQuery) String() string {
+	return q.Src.String() + ".GroupBy(" + q.Key.String() + ")"
+}
+func (q *JoinQuery) String() string {
+	return fmt.Sprintf("%s.Join(%s, %s, %s, %s)", q.Outer, q.Inner, q.OuterKey, q.InnerKey, q.Result)
+}
+
+func (q *OrderByQuery) String() string {
+	var b strings.Builder
+	b.WriteString(q.Src.String())
+	for i, k := range q.Keys {
+		b.WriteString([...]string{".OrderBy", ".ThenBy"}[min(i, 1)])
+		if k.Desc {
+			b.WriteString("Descending")
+		}
+		b.WriteString("(" + k.Key.String() + ")")
+	}
+	return b.String()
+}
+
+// Scope is what the expressions on the elements of q see,
+// Group is not nil if the elements are the groups of a GroupBy,
+// Inner is not nil in the Result of a Join, where Elem is the outer element
+type Scope struct {
+	Elem  reflect.Type // the element, or the element of the group
+	Group *GroupByQuery
+	Inner reflect.Type
+}
+
+func ScopeOf(q Query) Scope {
+	switch q := q.(type) {
+	case *GroupByQuery:
+		return Scope{Elem: q.Src.Elem(), Group: q}
+	case *WhereQuery:
+		return ScopeOf(q.Src)
+	case *OrderByQuery:
+		return ScopeOf(q.Src)
+	case *TakeQuery:
+		return ScopeOf(q.Src)
+	case *SkipQuery:
+		return ScopeOf(q.Src)
+	default:
+		return Scope{Elem: q.Elem()}
+	}
+}
+
+// ↓↓↓↓↓↓ Queryable ↓↓↓↓↓↓
+
+// Provider executes or translates the tree,
+// the elements of the result are of type q.Elem()
+type Provider interface {
+	Execute(q Query) (linq.Seq[any], error)
+}
+
+type Queryable[T any] struct {
+	Query    Query
+	Provider Provider
+}
+
+type OrderedQueryable[T any] struct {
+	Queryable[T]
+}
+
+// FromSeq queries xs in memory with SeqProvider
+func FromSeq[T any](xs linq.Seq[T]) Queryable[T] {
+	src := &SourceQuery{T: typeOf[T](), Seq: linq.Select(xs, func(x T) any { return x })}
+	return Queryable[T]{src, SeqProvider{}}
+}
+
+// Table queries the table name by the provider p, the fields of T are the columns
+func Table[T any](name string, p Provider) Queryable[T] {
+	return Queryable[T]{&SourceQuery{Name: name, T: typeOf[T]()}, p}
+}
+
+func (q Queryable[T]) String() string { return q.Query.String() }
+
+// Seq executes the query on the first Next, the error of the provider is reported by Err
+func (q Queryable[T]) Seq() linq.Seq[T] {
+	return linq.Defer(func() linq.Seq[T] {
+		xs, err := q.Provider.Execute(q.Query)
+		if err != nil {
+			return linq.Fail[T](err)
+		}
+		return linq.Select(xs, func(x any) T { return x.(T) })
+	})
+}
+
+func ToSlice[T any](q Queryable[T]) ([]T, error) {
+	return linq.ToSliceE(q.Seq())
+}
+
+func with[R, T any](q Queryable[T], query Query) Queryable[R] {
+	return Queryable[R]{query, q.Provider}
+}
+
+// ↓↓↓↓↓↓ Operators ↓↓↓↓↓↓
+
+func Where[T any](q Queryable[T], pred Expr[bool]) Queryable[T] {
+	return with[T](q, &WhereQuery{q.Query, pred.Node})
+}
+
+func Select[T, R any](q Queryable[T], proj Expr[R]) Queryable[R] {
+	return with[R](q, &SelectQuery{q.Query, proj.Node})
+}
+
+func OrderBy[T any, K linq.Ordered](q Queryable[T], key Expr[K]) OrderedQueryable[T] {
+	return OrderedQueryable[T]{with[T](q, &OrderByQuery{q.Query, []SortKey{{key.Node, false}}})}
+}
+
+func OrderByDescending[T any, K linq.Ordered](q Queryable[T], key Expr[K]) OrderedQueryable[T] {
+	return OrderedQueryable[T]{with[T](q, &OrderByQuery{q.Query, []SortKey{{key.Node, true}}})}
+}
+
+func ThenBy[T any, K linq.Ordered](q OrderedQueryable[T], key Expr[K]) OrderedQueryable[T] {
+	return q.thenBy(SortKey{key.Node, false})
+}
+
+func ThenByDescending[T any, K linq.Ordered](q OrderedQueryable[T], key Expr[K]) OrderedQueryable[T] {
+	return q.thenBy(SortKey{key.Node, true})
+}
+
+func (q OrderedQueryable[T]) thenBy(key SortKey) OrderedQueryable[T] {
+	o := q.Query.(*OrderByQuery)
+	keys := append(o.Keys[:len(o.Keys):len(o.Keys)], key)
+	return OrderedQueryable[T]{with[T](q.Queryable, &OrderByQuery{o.Src, keys})}
+}
+
+func Take[T any](q Queryable[T], n int) Queryable[T] {
+	return with[T](q, &TakeQuery{q.Query, n})
+}
+
+func Skip[T any](q Queryable[T], n int) Queryable[T] {
+	return with[T](q, &SkipQuery{q.Query, n})
+}
+
+func GroupBy[T any, K comparable](q Queryable[T], key Expr[K]) Queryable[linq.Grouping[K, T]] {
+	return with[linq.Grouping[K, T]](q, &GroupByQuery{
+		Src: q.Query,
+		Key: key.Node,
+		T:   typeOf[linq.Grouping[K, T]](),
+		Group: func(key any, elems []any) any {
+			xs := make([]T, len(elems))
+			for i, x := range elems {
+				xs[i] = x.(T)
+			}
+			return linq.Grouping[K, T]{Key: key.(K), Seq: linq.FromSlice(xs)}
+		},
+	})
+}
+
+// Join runs on the provider of outer
+func Join[O, I any, K comparable, R any](
+	outer Queryable[O],
+	inner Queryable[I],
+	outerKey Expr[K],
+	innerKey Expr[K],
+	result Expr[R],
+) Queryable[R] {
+	return with[R](outer, &JoinQuery{outer.Query, inner.Query, outerKey.Node, innerKey.Node, result.Node})
+}
@@ -0,0 +1,197 @@
+package queryable
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goghcrow/go-linq-object"
+)
+
+func assertEqual(t *testing.T, x, y any) {
+	if !reflect.DeepEqual(x, y) {
+		t.Fail()
+	}
+}
+
+type Pet struct {
+	Name  string
+	Owner string
+	Age   int
+}
+
+var pets = []Pet{
+	{"Barley", "Terry", 8},
+	{"Boots", "Terry", 4},
+	{"Whiskers", "Charlotte", 1},
+	{"Daisy", "Magnus", 4},
+}
+
+func TestQuery(t *testing.T) {
+	q := FromSeq(linq.FromSlice(pets))
+	age, name := Field[int]("Age"), Field[string]("Name")
+
+	{
+		old := Where(q, Gt(age, Const(3)))
+		names, err := ToSlice(Select(old, name))
+		assertEqual(t, err, nil)
+		assertEqual(t, names, []string{"Barley", "Boots", "Daisy"})
+	}
+	{
+		ordered := ThenBy(OrderByDescending(q, age), name)
+		xs, err := ToSlice(Select(Take(Skip(ordered.Queryable, 1), 2), name))
+		assertEqual(t, err, nil)
+		assertEqual(t, xs, []string{"Boots", "Daisy"})
+	}
+	{
+		type Row struct {
+			Name string
+			Next int
+		}
+		xs, err := ToSlice(Select(Where(q, Eq(Mod(age, Const(2)), Const(0))), New[Row](
+			Set("Name", name),
+			Set("Next", Add(age, Const(1))),
+		)))
+		assertEqual(t, err, nil)
+		assertEqual(t, xs, []Row{{"Barley", 9}, {"Boots", 5}, {"Daisy", 5}})
+	}
+	{
+		xs, err := ToSlice(Select(FromSeq(linq.Range(1, 6)), Mul(Elem[int](), Neg(Elem[int]()))))
+		assertEqual(t, err, nil)
+		assertEqual(t, xs, []int{-1, -4, -9, -16, -25})
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	q := FromSeq(linq.FromSlice(pets))
+	owner, age := Field[string]("Owner"), Field[int]("Age")
+
+	{
+		gs, err := ToSlice(GroupBy(q, owner))
+		assertEqual(t, err, nil)
+		assertEqual(t, len(gs), 3)
+		assertEqual(t, gs[0].Key, "Terry")
+		assertEqual(t, linq.ToSlice[Pet](gs[0].Seq), pets[:2])
+	}
+	{
+		type Row struct {
+			Owner string
+			Count int
+			Sum   int
+			Max   int
+			Avg   float64
+		}
+		g := GroupBy(q, owner)
+		xs, err := ToSlice(Select(OrderBy(Where(g, Ge(Count(), Const(1))), Key[string]()).Queryable, New[Row](
+			Set("Owner", Key[string]()),
+			Set("Count", Count()),
+			Set("Sum", Sum(age)),
+			Set("Max", Max(age)),
+			Set("Avg", Avg(age)),
+		)))
+		assertEqual(t, err, nil)
+		assertEqual(t, xs, []Row{
+			{"Charlotte", 1, 1, 1, 1},
+			{"Magnus", 1, 4, 4, 4},
+			{"Terry", 2, 12, 8, 6},
+		})
+	}
+	{
+		g := Where(GroupBy(q, owner), Gt(Count(), Const(1)))
+		xs, err := ToSlice(Select(g, Min(Field[string]("Name"))))
+		assertEqual(t, err, nil)
+		assertEqual(t, xs, []string{"Barley"})
+	}
+}
+
+type Person struct {
+	Name string
+	City string
+}
+
+var people = []Person{
+	{"Terry", "Leeds"},
+	{"Magnus", "Oslo"},
+}
+
+func TestJoin(t *testing.T) {
+	type Row struct {
+		Pet  string
+		City string
+	}
+	q := Join(
+		FromSeq(linq.FromSlice(pets)),
+		FromSeq(linq.FromSlice(people)),
+		Field[string]("Owner"),
+		Field[string]("Name"),
+		New[Row](
+			Set("Pet", OuterField[string]("Name")),
+			Set("City", InnerField[string]("City")),
+		),
+	)
+	xs, err := ToSlice(Where(q, Ne(Field[string]("City"), Const("Oslo"))))
+	assertEqual(t, err, nil)
+	assertEqual(t, xs, []Row{{"Barley", "Leeds"}, {"Boots", "Leeds"}})
+
+	_, err = ToSlice(Join(q, q, Field[string]("Pet"), Field[string]("Pet"), Field[string]("Pet")))
+	assertEqual(t, err != nil, true)
+}
+
+func TestString(t *testing.T) {
+	q := Table[Pet]("pets", nil)
+	q = Where(q, And(Gt(Field[int]("Age"), Const(3)), Not(Eq(Field[string]("Owner"), Const("Terry")))))
+	o := ThenByDescending(OrderBy(q, Field[string]("Name")), Field[int]("Age"))
+	s := Select(Take(o.Queryable, 2), Field[string]("Name"))
+	assertEqual(t, s.String(),
+		`pets.Where(((Age > 3) && !(Owner == "Terry"))).OrderBy(Name).ThenByDescending(Age).Take(2).Select(Name)`)
+
+	g := GroupBy(Table[Pet]("pets", nil), Field[string]("Owner"))
+	assertEqual(t, Select(g, Sum(Field[int]("Age"))).String(), "pets.GroupBy(Owner).Select(Sum(Age))")
+}
+
+// recorder is a Provider that records the trees it executes
+type recorder struct {
+	SeqProvider
+	trees []string
+}
+
+func (r *recorder) Execute(q Query) (linq.Seq[any], error) {
+	r.trees = append(r.trees, q.String())
+	return r.SeqProvider.Execute(q)
+}
+
+func TestProvider(t *testing.T) {
+	r := &recorder{}
+	q := FromSeq(linq.FromSlice(pets))
+	q.Provider = r
+
+	xs := Select(Where(q, Lt(Field[int]("Age"), Const(2))), Field[string]("Name")).Seq()
+	assertEqual(t, r.trees, []string(nil))
+	assertEqual(t, linq.ToSlice(xs), []string{"Whiskers"})
+	assertEqual(t, r.trees, []string{"Seq[queryable.Pet].Where((Age < 2)).Select(Name)"})
+}
+
+func TestErr(t *testing.T) {
+	q := FromSeq(linq.FromSlice(pets))
+	for _, err := range []error{
+		errOf(Select(q, Field[string]("Color"))),
+		errOf(Select(q, Field[string]("Age"))),
+		errOf(Select(q, Elem[int]())),
+		errOf(Select(q, Key[string]())),
+		errOf(Select(q, Count())),
+		errOf(Select(GroupBy(q, Field[string]("Owner")), Field[int]("Age"))),
+		errOf(Select(GroupBy(q, Field[string]("Owner")), Key[int]())),
+		errOf(Table[Pet]("pets", SeqProvider{})),
+	} {
+		assertEqual(t, err != nil, true)
+	}
+
+	xs := Select(q, Field[string]("Color")).Seq()
+	_, ok := xs.Next()
+	assertEqual(t, ok, false)
+	assertEqual(t, linq.Err(xs).Error(), "queryable: queryable.Pet has no field Color")
+}
+
+func errOf[T any](q Queryable[T]) error {
+	_, err := ToSlice(q)
+	return err
+}
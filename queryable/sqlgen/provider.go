@@ -0,0 +1,79 @@
+package sqlgen
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/goghcrow/go-linq-object"
+	"github.com/goghcrow/go-linq-object/queryable"
+)
+
+// ↓↓↓↓↓↓ Provider ↓↓↓↓↓↓
+
+// Provider runs the translated query on DB, the columns of a row are
+// scanned into the fields of the element by name
+type Provider struct {
+	Dialect Dialect
+	DB      *sql.DB
+}
+
+func (p Provider) Execute(q queryable.Query) (linq.Seq[any], error) {
+	text, args, scalar, err := translate(p.Dialect, q)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := p.DB.Query(text, args...)
+	if err != nil {
+		return nil, err
+	}
+	scan, err := scanner(rows, q.Elem(), scalar)
+	if err != nil {
+		_ = rows.Close()
+		return nil, err
+	}
+	return linq.Using(func() *sql.Rows { return rows }, func(rows *sql.Rows) linq.Seq[any] {
+		return linq.SeqOfE(func() (any, bool, error) {
+			if !rows.Next() {
+				return nil, false, rows.Err()
+			}
+			x, err := scan()
+			return x, err == nil, err
+		})
+	}), nil
+}
+
+// scanner scans a row into a new value of t
+func scanner(rows *sql.Rows, t reflect.Type, scalar bool) (func() (any, error), error) {
+	if scalar {
+		return func() (any, error) {
+			v := reflect.New(t)
+			err := rows.Scan(v.Interface())
+			return v.Elem().Interface(), err
+		}, nil
+	}
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string][]int{}
+	for _, f := range columns(t) {
+		fields[column(f)] = f.Index
+	}
+	idx := make([][]int, len(cols))
+	for i, c := range cols {
+		if idx[i] = fields[c]; idx[i] == nil {
+			return nil, fmt.Errorf("sqlgen: %s has no field for column %s", t, c)
+		}
+	}
+	return func() (any, error) {
+		v := reflect.New(t).Elem()
+		dest := make([]any, len(idx))
+		for i, index := range idx {
+			dest[i] = v.FieldByIndex(index).Addr().Interface()
+		}
+		err := rows.Scan(dest...)
+		return v.Interface(), err
+	}, nil
+}
@@ -0,0 +1,627 @@
+// Package sqlgen translates the queryable tree into parameterized SQL,
+// and runs it by database/sql.
+//
+//	pets := queryable.Table[Pet]("pets", sqlgen.Provider{Dialect: sqlgen.Postgres, DB: db})
+//	q := queryable.Where(pets, queryable.Gt(queryable.Field[int]("Age"), queryable.Const(3)))
+//	text, args, err := sqlgen.Translate(sqlgen.Postgres, q.Query)
+//	// SELECT "name", "owner", "age" FROM "pets" WHERE ("age" > $1) [3]
+//
+// The columns are the exported fields of the element, named by the db tag or the field name.
+// A query is translated into a single statement, an operator that can't follow
+// the clauses built so far wraps them into a subquery.
+package sqlgen
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goghcrow/go-linq-object/queryable"
+)
+
+// ↓↓↓↓↓↓ Dialect ↓↓↓↓↓↓
+
+type Dialect struct {
+	Name string
+	// Placeholder of the nth argument, starting at 1
+	Placeholder func(n int) string
+	Quote       func(ident string) string
+	// IntDiv is the operator of integer division
+	IntDiv string
+	// NoLimit is the LIMIT of an OFFSET without Take, empty if OFFSET goes alone
+	NoLimit string
+}
+
+var (
+	Postgres = Dialect{
+		Name:        "postgres",
+		Placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+		Quote:       quote(`"`),
+		IntDiv:      "/",
+	}
+	MySQL = Dialect{
+		Name:        "mysql",
+		Placeholder: func(int) string { return "?" },
+		Quote:       quote("`"),
+		IntDiv:      "DIV",
+		NoLimit:     "18446744073709551615",
+	}
+	SQLite = Dialect{
+		Name:        "sqlite",
+		Placeholder: func(int) string { return "?" },
+		Quote:       quote(`"`),
+		IntDiv:      "/",
+		NoLimit:     "-1",
+	}
+)
+
+func quote(q string) func(string) string {
+	return func(ident string) string {
+		return q + strings.ReplaceAll(ident, q, q+q) + q
+	}
+}
+
+// ↓↓↓↓↓↓ Fragment ↓↓↓↓↓↓
+
+// param marks the position of an argument in the text of a frag,
+// it is replaced with the placeholder of the dialect at last
+const param = "\x00"
+
+// frag is a piece of SQL with the arguments in text order
+type frag struct {
+	text string
+	args []any
+}
+
+// sprintf formats the texts of fs by %s in order
+func sprintf(format string, fs ...frag) frag {
+	texts := make([]any, len(fs))
+	var args []any
+	for i, f := range fs {
+		texts[i] = f.text
+		args = append(args, f.args...)
+	}
+	return frag{fmt.Sprintf(format, texts...), args}
+}
+
+func join(fs []frag, sep string) frag {
+	var r frag
+	for i, f := range fs {
+		if i > 0 {
+			r.text += sep
+		}
+		r.text += f.text
+		r.args = append(r.args, f.args...)
+	}
+	return r
+}
+
+func text(s string) frag { return frag{text: s} }
+
+// ↓↓↓↓↓↓ Translate ↓↓↓↓↓↓
+
+// Translate returns the SQL text of q and its arguments
+func Translate(d Dialect, q queryable.Query) (string, []any, error) {
+	text, args, _, err := translate(d, q)
+	return text, args, err
+}
+
+// translate also reports whether a row is a single scalar column
+func translate(d Dialect, q queryable.Query) (string, []any, bool, error) {
+	t := &translator{d: d}
+	s, err := t.query(q)
+	if err != nil {
+		return "", nil, false, err
+	}
+	f, err := t.render(s, false)
+	if err != nil {
+		return "", nil, false, err
+	}
+	parts := strings.Split(f.text, param)
+	var b strings.Builder
+	for i, p := range parts {
+		if i > 0 {
+			b.WriteString(d.Placeholder(i))
+		}
+		b.WriteString(p)
+	}
+	return b.String(), f.args, s.scalar, nil
+}
+
+// scope resolves the expressions on the current rows
+type scope struct {
+	elem   reflect.Type
+	scalar bool     // the row is the single column it
+	fields []string // the columns of elem in the row, nil for all
+	qual   string   // qualifier of the columns, empty if there is only one source
+	group  queryable.Node
+	inner  *scope // in the result of Join, the scope itself is the outer
+}
+
+// stmt is a SELECT being built, the clauses are in the order of SQL
+type stmt struct {
+	from    frag
+	where   []frag
+	groupBy *frag
+	having  []frag
+	cols    []frag // nil for all the columns of elem
+	sorts   []frag // the hidden columns of the sort keys, for the outer query
+	orderBy []sortKey
+	limit   int // -1 for none
+	offset  int
+
+	sc    scope
+	table bool // from is a table
+	// the rows after the projection
+	projected bool
+	elem      reflect.Type
+	scalar    bool
+	fields    []string // the columns assigned by New, nil for all
+}
+
+type sortKey struct {
+	x    frag
+	desc bool
+}
+
+type translator struct {
+	d       Dialect
+	aliases int
+	sorts   int
+}
+
+func (t *translator) alias() string {
+	t.aliases++
+	return t.d.Quote("t" + strconv.Itoa(t.aliases))
+}
+
+// carrySort selects the sort keys of s as hidden columns, since a subquery
+// doesn't keep its order, and returns the keys on them for the outer query,
+// s keeps ORDER BY only for its LIMIT / OFFSET
+func (t *translator) carrySort(s *stmt, qual string) []sortKey {
+	if s.orderBy == nil {
+		return nil
+	}
+	defer func() {
+		if s.limit < 0 && s.offset == 0 {
+			s.orderBy = nil
+		}
+	}()
+	keys := make([]sortKey, len(s.orderBy))
+	for i, k := range s.orderBy {
+		t.sorts++
+		name := "o" + strconv.Itoa(t.sorts)
+		s.sorts = append(s.sorts, sprintf("%s AS %s", k.x, text(t.d.Quote(name))))
+		keys[i] = sortKey{text(t.column(scope{qual: qual}, name)), k.desc}
+	}
+	return keys
+}
+
+// simple reports whether s selects the whole rows of a table
+func (s *stmt) simple() bool {
+	return s.table && !s.projected && s.where == nil && s.groupBy == nil && s.orderBy == nil && s.limit < 0 && s.offset == 0
+}
+
+func (t *translator) query(q queryable.Query) (*stmt, error) {
+	switch q := q.(type) {
+	case *queryable.SourceQuery:
+		if q.Name == "" {
+			return nil, fmt.Errorf("sqlgen: %s is not a table", q)
+		}
+		return &stmt{from: text(t.d.Quote(q.Name)), limit: -1, sc: scope{elem: q.T}, table: true, elem: q.T}, nil
+
+	case *queryable.WhereQuery:
+		s, err := t.src(q.Src, func(s *stmt) bool { return s.projected || s.limit >= 0 || s.offset > 0 })
+		if err != nil {
+			return nil, err
+		}
+		pred, err := t.expr(q.Pred, s.sc)
+		if err != nil {
+			return nil, err
+		}
+		if s.groupBy != nil {
+			s.having = append(s.having, pred)
+		} else {
+			s.where = append(s.where, pred)
+		}
+		return s, nil
+
+	case *queryable.SelectQuery:
+		s, err := t.src(q.Src, func(s *stmt) bool { return s.projected })
+		if err != nil {
+			return nil, err
+		}
+		if s.cols, err = t.projection(q.Proj, s.sc); err != nil {
+			return nil, err
+		}
+		s.project(q.Proj)
+		return s, nil
+
+	case *queryable.OrderByQuery:
+		s, err := t.src(q.Src, func(s *stmt) bool { return s.projected || s.limit >= 0 || s.offset > 0 })
+		if err != nil {
+			return nil, err
+		}
+		// a later OrderBy sorts stably, so the former keys follow the new ones
+		keys := make([]sortKey, 0, len(q.Keys)+len(s.orderBy))
+		for _, k := range q.Keys {
+			key, err := t.expr(k.Key, s.sc)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, sortKey{key, k.Desc})
+		}
+		s.orderBy = append(keys, s.orderBy...)
+		return s, nil
+
+	case *queryable.TakeQuery:
+		s, err := t.query(q.Src)
+		if err != nil {
+			return nil, err
+		}
+		if n := max(q.N, 0); s.limit < 0 || n < s.limit {
+			s.limit = n
+		}
+		return s, nil
+
+	case *queryable.SkipQuery:
+		s, err := t.query(q.Src)
+		if err != nil {
+			return nil, err
+		}
+		n := max(q.N, 0)
+		if s.limit >= 0 {
+			s.limit = max(s.limit-n, 0)
+		}
+		s.offset += n
+		return s, nil
+
+	case *queryable.GroupByQuery:
+		s, err := t.src(q.Src, func(s *stmt) bool {
+			return s.projected || s.groupBy != nil || s.limit >= 0 || s.offset > 0
+		})
+		if err != nil {
+			return nil, err
+		}
+		key, err := t.expr(q.Key, s.sc)
+		if err != nil {
+			return nil, err
+		}
+		// the order of the groups is up to the database
+		s.groupBy, s.orderBy, s.sc.group = &key, nil, q.Key
+		return s, nil
+
+	case *queryable.JoinQuery:
+		return t.join(q)
+	}
+	return nil, fmt.Errorf("sqlgen: cannot translate %T", q)
+}
+
+// src translates q, and wraps it into a subquery if the next operator can't follow
+func (t *translator) src(q queryable.Query, needWrap func(*stmt) bool) (*stmt, error) {
+	s, err := t.query(q)
+	if err != nil || !needWrap(s) {
+		return s, err
+	}
+	return t.wrap(s)
+}
+
+func (t *translator) wrap(s *stmt) (*stmt, error) {
+	orderBy := t.carrySort(s, "")
+	sub, err := t.render(s, true)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{
+		from:    sprintf("(%s) AS %s", sub, text(t.alias())),
+		orderBy: orderBy,
+		limit:   -1,
+		sc:      scope{elem: s.elem, scalar: s.scalar, fields: s.fields},
+		elem:    s.elem,
+		scalar:  s.scalar,
+		fields:  s.fields,
+	}, nil
+}
+
+func (t *translator) join(q *queryable.JoinQuery) (*stmt, error) {
+	var sides [2]scope
+	var froms [2]frag
+	// the rows follow the order of the outer, then of the inner
+	var orderBy []sortKey
+	for i, src := range []queryable.Query{q.Outer, q.Inner} {
+		s, err := t.query(src)
+		if err != nil {
+			return nil, err
+		}
+		alias := t.alias()
+		if s.simple() {
+			froms[i] = sprintf("%s AS %s", s.from, text(alias))
+		} else {
+			orderBy = append(orderBy, t.carrySort(s, alias)...)
+			sub, err := t.render(s, true)
+			if err != nil {
+				return nil, err
+			}
+			froms[i] = sprintf("(%s) AS %s", sub, text(alias))
+		}
+		sides[i] = scope{elem: s.elem, scalar: s.scalar, fields: s.fields, qual: alias}
+	}
+	outerKey, err := t.expr(q.OuterKey, sides[0])
+	if err != nil {
+		return nil, err
+	}
+	innerKey, err := t.expr(q.InnerKey, sides[1])
+	if err != nil {
+		return nil, err
+	}
+	sc := sides[0]
+	sc.inner = &sides[1]
+	cols, err := t.projection(q.Result, sc)
+	if err != nil {
+		return nil, err
+	}
+	s := &stmt{
+		from:    sprintf("%s JOIN %s ON %s = %s", froms[0], froms[1], outerKey, innerKey),
+		cols:    cols,
+		orderBy: orderBy,
+		limit:   -1,
+	}
+	s.project(q.Result)
+	return s, nil
+}
+
+func (s *stmt) project(proj queryable.Node) {
+	n, isNew := proj.(*queryable.NewExpr)
+	s.projected, s.elem, s.scalar, s.fields = true, proj.Type(), !isNew, nil
+	if isNew {
+		s.fields = []string{}
+		for _, a := range n.Fields {
+			f, _ := n.T.FieldByName(a.Name)
+			s.fields = append(s.fields, column(f))
+		}
+	}
+}
+
+func (t *translator) render(s *stmt, sub bool) (frag, error) {
+	if s.groupBy != nil && !s.projected {
+		return frag{}, fmt.Errorf("sqlgen: GroupBy must be followed by Select")
+	}
+	cols := s.cols
+	switch {
+	case cols == nil && s.scalar:
+		cols = []frag{text(t.d.Quote("it"))}
+	case cols == nil && s.fields != nil:
+		for _, c := range s.fields {
+			cols = append(cols, text(t.d.Quote(c)))
+		}
+	case cols == nil:
+		if s.elem.Kind() != reflect.Struct {
+			return frag{}, fmt.Errorf("sqlgen: %s is not a struct", s.elem)
+		}
+		for _, f := range columns(s.elem) {
+			cols = append(cols, text(t.d.Quote(column(f))))
+		}
+	case s.scalar && sub:
+		cols = []frag{sprintf("%s AS %s", cols[0], text(t.d.Quote("it")))}
+	}
+
+	r := sprintf("SELECT %s FROM %s", join(slices.Concat(cols, s.sorts), ", "), s.from)
+	if s.where != nil {
+		r = sprintf("%s WHERE %s", r, join(s.where, " AND "))
+	}
+	if s.groupBy != nil {
+		r = sprintf("%s GROUP BY %s", r, *s.groupBy)
+	}
+	if s.having != nil {
+		r = sprintf("%s HAVING %s", r, join(s.having, " AND "))
+	}
+	if s.orderBy != nil {
+		keys := make([]frag, len(s.orderBy))
+		for i, k := range s.orderBy {
+			if keys[i] = k.x; k.desc {
+				keys[i].text += " DESC"
+			}
+		}
+		r = sprintf("%s ORDER BY %s", r, join(keys, ", "))
+	}
+	switch {
+	case s.limit >= 0:
+		r.text += " LIMIT " + strconv.Itoa(s.limit)
+	case s.offset > 0 && t.d.NoLimit != "":
+		r.text += " LIMIT " + t.d.NoLimit
+	}
+	if s.offset > 0 {
+		r.text += " OFFSET " + strconv.Itoa(s.offset)
+	}
+	return r, nil
+}
+
+// projection translates the columns of Select, New names them after the fields
+func (t *translator) projection(proj queryable.Node, sc scope) ([]frag, error) {
+	n, ok := proj.(*queryable.NewExpr)
+	if !ok {
+		x, err := t.expr(proj, sc)
+		return []frag{x}, err
+	}
+	if n.T.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("sqlgen: New %s is not a struct", n.T)
+	}
+	cols := make([]frag, len(n.Fields))
+	for i, a := range n.Fields {
+		f, ok := n.T.FieldByName(a.Name)
+		if !ok || !f.IsExported() {
+			return nil, fmt.Errorf("sqlgen: %s has no field %s", n.T, a.Name)
+		}
+		if a.X.Type() != f.Type {
+			return nil, fmt.Errorf("sqlgen: field %s of %s is %s, not %s", a.Name, n.T, f.Type, a.X.Type())
+		}
+		x, err := t.expr(a.X, sc)
+		if err != nil {
+			return nil, err
+		}
+		cols[i] = sprintf("%s AS %s", x, text(t.d.Quote(column(f))))
+	}
+	return cols, nil
+}
+
+var binaryOps = [...]string{
+	queryable.OpEq:  "=",
+	queryable.OpNe:  "<>",
+	queryable.OpLt:  "<",
+	queryable.OpLe:  "<=",
+	queryable.OpGt:  ">",
+	queryable.OpGe:  ">=",
+	queryable.OpAdd: "+",
+	queryable.OpSub: "-",
+	queryable.OpMul: "*",
+	queryable.OpDiv: "/",
+	queryable.OpMod: "%",
+	queryable.OpAnd: "AND",
+	queryable.OpOr:  "OR",
+}
+
+func (t *translator) expr(n queryable.Node, sc scope) (frag, error) {
+	switch n := n.(type) {
+	case *queryable.FieldExpr:
+		switch {
+		case sc.group != nil:
+			return frag{}, fmt.Errorf("sqlgen: field %s after GroupBy is not in an aggregate", n)
+		case sc.inner != nil && n.Of == queryable.SideElem:
+			return frag{}, fmt.Errorf("sqlgen: field %s in the result of Join is neither OuterField nor InnerField", n)
+		case sc.inner == nil && n.Of != queryable.SideElem:
+			return frag{}, fmt.Errorf("sqlgen: %s outside the result of Join", n)
+		case n.Of == queryable.SideInner:
+			sc = *sc.inner
+		}
+		if sc.scalar || sc.elem.Kind() != reflect.Struct {
+			return frag{}, fmt.Errorf("sqlgen: field %s of %s", n.Name, sc.elem)
+		}
+		f, ok := sc.elem.FieldByName(n.Name)
+		if !ok || !f.IsExported() {
+			return frag{}, fmt.Errorf("sqlgen: %s has no field %s", sc.elem, n.Name)
+		}
+		if f.Type != n.T {
+			return frag{}, fmt.Errorf("sqlgen: field %s is %s, not %s", n.Name, f.Type, n.T)
+		}
+		if f.Tag.Get("db") == "-" || sc.fields != nil && !slices.Contains(sc.fields, column(f)) {
+			return frag{}, fmt.Errorf("sqlgen: field %s of %s is not a column", n.Name, sc.elem)
+		}
+		return text(t.column(sc, column(f))), nil
+
+	case *queryable.ElemExpr:
+		if !sc.scalar || sc.group != nil || sc.inner != nil || sc.elem != n.T {
+			return frag{}, fmt.Errorf("sqlgen: cannot translate the element %s as a column", n.T)
+		}
+		return text(t.column(sc, "it")), nil
+
+	case *queryable.ConstExpr:
+		if !isParam(n.Value) {
+			return frag{}, fmt.Errorf("sqlgen: constant of %s cannot be an argument", n.T)
+		}
+		return frag{param, []any{n.Value}}, nil
+
+	case *queryable.UnaryExpr:
+		x, err := t.expr(n.X, sc)
+		if err != nil {
+			return frag{}, err
+		}
+		if n.Op == queryable.OpNot {
+			return sprintf("NOT %s", x), nil
+		}
+		// -- starts a comment
+		return sprintf("-(%s)", x), nil
+
+	case *queryable.BinaryExpr:
+		if n.L.Type() != n.R.Type() {
+			return frag{}, fmt.Errorf("sqlgen: %s: mismatched types %s and %s", n, n.L.Type(), n.R.Type())
+		}
+		l, err := t.expr(n.L, sc)
+		if err != nil {
+			return frag{}, err
+		}
+		r, err := t.expr(n.R, sc)
+		if err != nil {
+			return frag{}, err
+		}
+		op := binaryOps[n.Op]
+		if n.Op == queryable.OpDiv && (n.T.Kind() >= reflect.Int && n.T.Kind() <= reflect.Uintptr) {
+			op = t.d.IntDiv
+		}
+		return sprintf("(%s %s %s)", l, text(op), r), nil
+
+	case *queryable.NewExpr:
+		return frag{}, fmt.Errorf("sqlgen: cannot translate %s, New is only allowed as the projection", n)
+
+	case *queryable.KeyExpr:
+		if sc.group == nil {
+			return frag{}, fmt.Errorf("sqlgen: Key without GroupBy")
+		}
+		if k := sc.group.Type(); k != n.T {
+			return frag{}, fmt.Errorf("sqlgen: Key is %s, not %s", k, n.T)
+		}
+		key := sc.group
+		sc.group = nil
+		return t.expr(key, sc)
+
+	case *queryable.AggExpr:
+		if sc.group == nil {
+			return frag{}, fmt.Errorf("sqlgen: %s without GroupBy", n.Fn)
+		}
+		if n.Fn == queryable.AggCount {
+			return text("COUNT(*)"), nil
+		}
+		sc.group = nil
+		x, err := t.expr(n.X, sc)
+		if err != nil {
+			return frag{}, err
+		}
+		return sprintf("%s(%s)", text(strings.ToUpper(n.Fn.String())), x), nil
+	}
+	return frag{}, fmt.Errorf("sqlgen: cannot translate %T", n)
+}
+
+func (t *translator) column(sc scope, name string) string {
+	if sc.qual == "" {
+		return t.d.Quote(name)
+	}
+	return sc.qual + "." + t.d.Quote(name)
+}
+
+// isParam reports whether database/sql takes x as an argument by default
+func isParam(x any) bool {
+	switch x.(type) {
+	case nil, []byte, time.Time, driver.Valuer:
+		return true
+	}
+	switch reflect.ValueOf(x).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Bool, reflect.String:
+		return true
+	}
+	return false
+}
+
+// ↓↓↓↓↓↓ Columns ↓↓↓↓↓↓
+
+// columns are the exported fields of t, except db:"-"
+func columns(t reflect.Type) []reflect.StructField {
+	var fs []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.IsExported() && f.Tag.Get("db") != "-" {
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
+
+func column(f reflect.StructField) string {
+	if name := f.Tag.Get("db"); name != "" {
+		return name
+	}
+	return f.Name
+}
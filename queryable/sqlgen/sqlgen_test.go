@@ -0,0 +1,231 @@
+package sqlgen
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goghcrow/go-linq-object/queryable"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func assertEqual(t *testing.T, x, y any) {
+	if !reflect.DeepEqual(x, y) {
+		t.Fail()
+	}
+}
+
+type Pet struct {
+	Name   string `db:"name"`
+	Owner  string `db:"owner"`
+	Age    int    `db:"age"`
+	Weight float64
+	Secret string `db:"-"`
+}
+
+type Person struct {
+	Name string `db:"name"`
+	City string `db:"city"`
+}
+
+type Row struct {
+	Name  string `db:"name"`
+	Count int    `db:"n"`
+	Age   int
+	Avg   float64
+}
+
+var (
+	pets   = queryable.Table[Pet]("pets", nil)
+	people = queryable.Table[Person]("people", nil)
+	age    = queryable.Field[int]("Age")
+	name   = queryable.Field[string]("Name")
+	owner  = queryable.Field[string]("Owner")
+)
+
+var queries = map[string]queryable.Query{
+	"where": queryable.Where(pets, queryable.And(queryable.Gt(age, queryable.Const(3)), queryable.Not(queryable.Eq(owner, queryable.Const("Terry"))))).Query,
+
+	"select": queryable.Select(queryable.Where(pets, queryable.Le(queryable.Field[float64]("Weight"), queryable.Const(2.5))), queryable.New[Row](
+		queryable.Set("Name", name),
+		queryable.Set("Age", queryable.Add(queryable.Mul(age, queryable.Const(2)), queryable.Const(1))),
+	)).Query,
+
+	"neg": queryable.Where(pets, queryable.Gt(queryable.Neg(queryable.Neg(age)), queryable.Const(1))).Query,
+
+	"arith": queryable.Select(pets, queryable.Add(queryable.Div(queryable.Neg(age), queryable.Const(2)), queryable.Mod(age, queryable.Const(3)))).Query,
+
+	"order": queryable.Select(
+		queryable.Take(queryable.Skip(queryable.ThenBy(queryable.OrderByDescending(pets, age), name).Queryable, 1), 2),
+		name,
+	).Query,
+
+	"skip": queryable.Skip(queryable.Skip(pets, 2), 3).Query,
+
+	"take_skip": queryable.Take(queryable.Skip(queryable.Take(pets, 10), 4), 8).Query,
+
+	"group": queryable.Select(
+		queryable.OrderByDescending(queryable.Where(queryable.GroupBy(queryable.Where(pets, queryable.Gt(age, queryable.Const(1))), owner), queryable.Gt(queryable.Count(), queryable.Const(1))), queryable.Sum(age)).Queryable,
+		queryable.New[Row](
+			queryable.Set("Name", queryable.Key[string]()),
+			queryable.Set("Count", queryable.Count()),
+			queryable.Set("Age", queryable.Max(age)),
+			queryable.Set("Avg", queryable.Avg(queryable.Field[float64]("Weight"))),
+		),
+	).Query,
+
+	"join": queryable.Where(queryable.Join(pets, queryable.Where(people, queryable.Ne(queryable.Field[string]("City"), queryable.Const("Oslo"))), owner, name, queryable.New[Row](
+		queryable.Set("Name", queryable.OuterField[string]("Name")),
+		queryable.Set("Age", queryable.OuterField[int]("Age")),
+	)), queryable.Lt(age, queryable.Const(10))).Query,
+
+	"join_order": queryable.Join(queryable.OrderBy(pets, age).Queryable, queryable.OrderByDescending(people, queryable.Field[string]("City")).Queryable, owner, name, queryable.New[Row](
+		queryable.Set("Name", queryable.InnerField[string]("Name")),
+		queryable.Set("Age", queryable.OuterField[int]("Age")),
+	)).Query,
+
+	"subquery": queryable.Where(
+		queryable.Select(queryable.Where(queryable.Take(queryable.OrderBy(pets, age).Queryable, 3), queryable.Ne(owner, queryable.Const("Terry"))), name),
+		queryable.Ne(queryable.Elem[string](), queryable.Const("Boots")),
+	).Query,
+}
+
+// TestGolden translates the queries for every dialect, and compares with testdata/*.golden
+func TestGolden(t *testing.T) {
+	for name, q := range queries {
+		t.Run(name, func(t *testing.T) {
+			var b strings.Builder
+			fmt.Fprintf(&b, "%s\n", q)
+			for _, d := range []Dialect{Postgres, MySQL, SQLite} {
+				text, args, err := Translate(d, q)
+				if err != nil {
+					t.Fatal(err)
+				}
+				fmt.Fprintf(&b, "-- %s --\n%s\n%#v\n", d.Name, text, args)
+			}
+			out := b.String()
+
+			golden := filepath.Join("testdata", name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(out), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != string(want) {
+				t.Errorf("%s differs from the golden file, got\n%s", golden, out)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	type Point struct{ X, Y int }
+	for want, q := range map[string]queryable.Query{
+		"is not a table":                       queryable.FromSeq[Pet](nil).Query,
+		"has no field Color":                   queryable.Select(pets, queryable.Field[string]("Color")).Query,
+		"field Age is int, not string":         queryable.Select(pets, queryable.Field[string]("Age")).Query,
+		"Secret of sqlgen.Pet is not a column": queryable.Select(pets, queryable.Field[string]("Secret")).Query,
+		"cannot translate the element":         queryable.Select(pets, queryable.Elem[Pet]()).Query,
+		"constant of sqlgen.Point":             queryable.Where(pets, queryable.Eq(queryable.Const(Point{}), queryable.Const(Point{}))).Query,
+		"New is only allowed":                  queryable.Select(pets, queryable.Eq(queryable.New[Point](), queryable.New[Point]())).Query,
+		"GroupBy must be followed":             queryable.GroupBy(pets, owner).Query,
+		"not in an aggregate":                  queryable.Select(queryable.GroupBy(pets, owner), age).Query,
+		"Key is string, not int":               queryable.Select(queryable.GroupBy(pets, owner), queryable.Key[int]()).Query,
+		"Count without GroupBy":                queryable.Select(pets, queryable.Count()).Query,
+		"neither OuterField nor InnerField":    queryable.Join(pets, people, owner, name, name).Query,
+		"outside the result of Join":           queryable.Select(pets, queryable.OuterField[string]("Name")).Query,
+		"int is not a struct":                  queryable.Table[int]("ints", nil).Query,
+	} {
+		t.Run(want, func(t *testing.T) {
+			_, _, err := Translate(Postgres, q)
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("got %v, want %s", err, want)
+			}
+		})
+	}
+}
+
+// fake is a driver that records the query, and returns cols and rows
+type fake struct {
+	query string
+	args  []driver.Value
+	cols  []string
+	rows  [][]driver.Value
+}
+
+func (f *fake) Open(string) (driver.Conn, error)           { return f, nil }
+func (f *fake) Prepare(query string) (driver.Stmt, error)  { f.query = query; return f, nil }
+func (f *fake) Close() error                               { return nil }
+func (f *fake) Begin() (driver.Tx, error)                  { return nil, driver.ErrSkip }
+func (f *fake) NumInput() int                              { return -1 }
+func (f *fake) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (f *fake) Query(args []driver.Value) (driver.Rows, error) {
+	f.args = args
+	return &fakeRows{f.cols, f.rows}, nil
+}
+
+type fakeRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// registered once, sql.Register panics on a second call, e.g. under -count=2
+var fakeDriver = &fake{}
+
+func init() {
+	sql.Register("sqlgen-fake", fakeDriver)
+}
+
+func TestProvider(t *testing.T) {
+	f := fakeDriver
+	*f = fake{}
+	db, err := sql.Open("sqlgen-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	p := Provider{Dialect: Postgres, DB: db}
+	pets := queryable.Table[Pet]("pets", p)
+
+	f.cols, f.rows = []string{"name", "owner", "age", "Weight"}, [][]driver.Value{
+		{"Boots", "Terry", int64(4), 2.0},
+		{"Daisy", "Magnus", int64(4), 2.2},
+	}
+	xs, err := queryable.ToSlice(queryable.Where(pets, queryable.Eq(age, queryable.Const(4))))
+	assertEqual(t, err, nil)
+	assertEqual(t, f.query, `SELECT "name", "owner", "age", "Weight" FROM "pets" WHERE ("age" = $1)`)
+	assertEqual(t, f.args, []driver.Value{int64(4)})
+	assertEqual(t, xs, []Pet{{"Boots", "Terry", 4, 2.0, ""}, {"Daisy", "Magnus", 4, 2.2, ""}})
+
+	f.cols, f.rows = []string{"name"}, [][]driver.Value{{"Boots"}}
+	names, err := queryable.ToSlice(queryable.Select(pets, name))
+	assertEqual(t, err, nil)
+	assertEqual(t, names, []string{"Boots"})
+
+	f.cols, f.rows = []string{"color"}, [][]driver.Value{{"red"}}
+	_, err = queryable.ToSlice(pets)
+	assertEqual(t, err.Error(), "sqlgen: sqlgen.Pet has no field for column color")
+}
@@ -1,11 +1,100 @@
 package linq
 
+import (
+	"errors"
+	"io"
+	"sort"
+)
+
 // Sequence Interface
 
 type Seq[T any] interface {
 	Next() (T, bool)
 }
 
+// Enumerable creates a fresh enumerator on every iteration, aka GetEnumerator,
+// operators keep the property when their inputs have it,
+// and Next consumes the default enumerator.
+type Enumerable[T any] interface {
+	Seq[T]
+	Enumerator() Seq[T]
+}
+
+// A sequence backed by a file, a db cursor or a goroutine may implement io.Closer,
+// operators pass Close on to their sources, Take closes its source once it has
+// delivered the requested count, First / Any / All close on short-circuit.
+// Close may be called more than once.
+
+func Close[T any](xs Seq[T]) error {
+	if c, ok := unwrap(xs).(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// SeqE reports why iteration stopped in the style of bufio.Scanner,
+// Next returns false on failure and Err returns the error,
+// operators pass the first error of their sources on.
+type SeqE[T any] interface {
+	Seq[T]
+	Err() error
+}
+
+type errer interface{ Err() error }
+
+func Err[T any](xs Seq[T]) error {
+	if e, ok := unwrap(xs).(errer); ok {
+		return e.Err()
+	}
+	return nil
+}
+
+// Sized knows the number of elements without enumerating them,
+// all elements of an Enumerable, the remaining ones of an enumerator.
+type Sized interface {
+	Len() int
+}
+
+// Indexed accesses the element i in O(1), for 0 <= i < Len(),
+// Count / ElementAt / Last / Skip / Reverse use it on an Enumerable.
+type Indexed[T any] interface {
+	Sized
+	At(i int) T
+}
+
+// indexedOf returns xs if it is an Indexed Enumerable, which neither
+// needs Close nor fails, so that reading it with At consumes nothing
+func indexedOf[T any](xs Seq[T]) (Indexed[T], bool) {
+	xs = unwrap(xs)
+	if _, ok := xs.(Enumerable[T]); !ok {
+		return nil, false
+	}
+	if _, ok := xs.(io.Closer); ok {
+		return nil, false
+	}
+	if _, ok := xs.(errer); ok {
+		return nil, false
+	}
+	s, ok := xs.(Indexed[T])
+	return s, ok
+}
+
+// unwrapper only forwards Next of the Seq it wraps, e.g. Query,
+// the operators look through it for the optional interfaces above
+type unwrapper[T any] interface {
+	unwrap() Seq[T]
+}
+
+func unwrap[T any](xs Seq[T]) Seq[T] {
+	for {
+		u, ok := xs.(unwrapper[T])
+		if !ok {
+			return xs
+		}
+		xs = u.unwrap()
+	}
+}
+
 // ↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓
 // Fun Sequence Implementations
 
@@ -13,6 +102,218 @@ type FSeq[T any] func() (T, bool)
 
 func (f FSeq[T]) Next() (T, bool) { return f() }
 
+// ↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓
+// Enumerable Implementations
+
+type FEnumerable[T any] struct {
+	f   func() Seq[T]
+	cur Seq[T]
+}
+
+func (e *FEnumerable[T]) Enumerator() Seq[T] { return e.f() }
+
+func (e *FEnumerable[T]) Next() (T, bool) {
+	if e.cur == nil {
+		e.cur = e.f()
+	}
+	return e.cur.Next()
+}
+
+// Close closes the default enumerator
+func (e *FEnumerable[T]) Close() error {
+	return Close(e.cur)
+}
+
+// Err reports the error of the default enumerator
+func (e *FEnumerable[T]) Err() error {
+	return Err(e.cur)
+}
+
+// ↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓
+// Fused Sequence Implementations
+
+// fusedSeq runs adjacent Select / Where stages in one Next,
+// f maps an element of src, or drops it with false
+type fusedSeq[S, R any] struct {
+	src Seq[S]
+	f   func(S) (R, bool)
+}
+
+func (s *fusedSeq[S, R]) Next() (r R, ok bool) {
+	for {
+		x, ok := s.src.Next()
+		if !ok {
+			return r, false
+		}
+		if r, ok = s.f(x); ok {
+			return r, true
+		}
+	}
+}
+
+func (s *fusedSeq[S, R]) Close() error { return Close(s.src) }
+func (s *fusedSeq[S, R]) Err() error   { return Err(s.src) }
+
+func (s *fusedSeq[S, R]) stage() (Seq[S], func(S) (R, bool)) { return s.src, s.f }
+func (s *fusedSeq[S, R]) where(p Pred[R]) Seq[R]             { return fuseWhere(s.src, s.f, p) }
+
+// fusedEnumerable is fusedSeq on a re-enumerable src
+type fusedEnumerable[S, R any] struct {
+	src Enumerable[S]
+	f   func(S) (R, bool)
+	cur *fusedSeq[S, R]
+}
+
+func (e *fusedEnumerable[S, R]) Enumerator() Seq[R] {
+	return &fusedSeq[S, R]{e.src.Enumerator(), e.f}
+}
+
+func (e *fusedEnumerable[S, R]) Next() (R, bool) {
+	if e.cur == nil {
+		e.cur = &fusedSeq[S, R]{e.src.Enumerator(), e.f}
+	}
+	return e.cur.Next()
+}
+
+// Close closes the default enumerator
+func (e *fusedEnumerable[S, R]) Close() error {
+	if e.cur == nil {
+		return nil
+	}
+	return e.cur.Close()
+}
+
+// Err reports the error of the default enumerator
+func (e *fusedEnumerable[S, R]) Err() error {
+	if e.cur == nil {
+		return nil
+	}
+	return e.cur.Err()
+}
+
+func (e *fusedEnumerable[S, R]) stage() (Seq[S], func(S) (R, bool)) { return e.src, e.f }
+func (e *fusedEnumerable[S, R]) where(p Pred[R]) Seq[R]             { return fuseWhere[S](e.src, e.f, p) }
+
+// stage is a fused Select / Where sequence,
+// Where fuses onto any stage, Select onto a stage of the same type, e.g. a Where
+type stage[S, R any] interface {
+	Seq[R]
+	stage() (Seq[S], func(S) (R, bool))
+}
+
+type whereStage[R any] interface {
+	where(p Pred[R]) Seq[R]
+}
+
+func fuse[S, R any](src Seq[S], f func(S) (R, bool)) Seq[R] {
+	src = unwrap(src)
+	if e, ok := src.(Enumerable[S]); ok {
+		return &fusedEnumerable[S, R]{src: e, f: f}
+	}
+	return &fusedSeq[S, R]{src, f}
+}
+
+func fuseWhere[S, R any](src Seq[S], f func(S) (R, bool), p Pred[R]) Seq[R] {
+	return fuse(src, func(x S) (R, bool) {
+		r, ok := f(x)
+		return r, ok && p(r)
+	})
+}
+
+// enumerator returns a fresh enumerator if xs is re-enumerable
+func enumerator[T any](xs Seq[T]) Seq[T] {
+	xs = unwrap(xs)
+	if e, ok := xs.(Enumerable[T]); ok {
+		return e.Enumerator()
+	}
+	return xs
+}
+
+// lift applies op to a fresh enumerator on every iteration if xs is re-enumerable,
+// so that the state of op is never shared between iterations
+func lift[A, R any](xs Seq[A], op func(Seq[A]) Seq[R]) Seq[R] {
+	xs = unwrap(xs)
+	if e, ok := xs.(Enumerable[A]); ok {
+		return EnumerableOf(func() Seq[R] {
+			xs := e.Enumerator()
+			return inherit(op(xs), xs)
+		})
+	}
+	return inherit(op(xs), xs)
+}
+
+func lift2[A, B, R any](xs Seq[A], ys Seq[B], op func(Seq[A], Seq[B]) Seq[R]) Seq[R] {
+	xs, ys = unwrap(xs), unwrap(ys)
+	ex, ok1 := xs.(Enumerable[A])
+	ey, ok2 := ys.(Enumerable[B])
+	if ok1 && ok2 {
+		return EnumerableOf(func() Seq[R] {
+			xs, ys := ex.Enumerator(), ey.Enumerator()
+			return inherit(inherit(op(xs, ys), xs), ys)
+		})
+	}
+	return inherit(inherit(op(xs, ys), xs), ys)
+}
+
+// ↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓
+// Closable and Error-carrying Sequence Implementations
+
+type wrapSeq[T any] struct {
+	Seq[T]
+	close func() error
+	err   func() error
+}
+
+func (s *wrapSeq[T]) Close() error {
+	if s.close == nil {
+		return nil
+	}
+	c := s.close
+	s.close = nil
+	return c()
+}
+
+func (s *wrapSeq[T]) Err() error {
+	if s.err == nil {
+		return nil
+	}
+	return s.err()
+}
+
+// onClose closes xs and then calls f
+func onClose[T any](xs Seq[T], f func() error) Seq[T] {
+	return &wrapSeq[T]{
+		Seq:   xs,
+		close: func() error { return errors.Join(Close(xs), f()) },
+		err:   func() error { return Err(xs) },
+	}
+}
+
+// inherit makes ys close xs and report the error of xs as well
+func inherit[R, A any](ys Seq[R], xs Seq[A]) Seq[R] {
+	xs = unwrap(xs)
+	c, closable := xs.(io.Closer)
+	e, failable := xs.(errer)
+	if !closable && !failable {
+		return ys
+	}
+	return &wrapSeq[R]{
+		Seq: ys,
+		close: func() error {
+			if closable {
+				return errors.Join(Close(ys), c.Close())
+			}
+			return Close(ys)
+		},
+		err: func() error {
+			if err := Err(ys); err != nil || !failable {
+				return err
+			}
+			return e.Err()
+		},
+	}
+}
+
 // ↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓
 // Slice Sequence Implementations
 
@@ -28,3 +329,161 @@ func (s *SliceSeq[T]) Next() (x T, ok bool) {
 	x, s.i = s.xs[s.i], s.i+1
 	return x, true
 }
+
+func (s *SliceSeq[T]) Len() int   { return len(s.xs) - s.i }
+func (s *SliceSeq[T]) At(i int) T { return s.xs[s.i+i] }
+
+// ↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓
+// Indexed Sequence Implementations
+
+// indexedEnumerable is the Enumerable of at(0) .. at(n-1),
+// xs is the slice of FromSlice, which SliceSeq enumerates faster
+type indexedEnumerable[T any] struct {
+	n   int
+	at  func(int) T
+	xs  []T
+	cur Seq[T]
+}
+
+func indexedEnumerableOf[T any](n int, at func(int) T) *indexedEnumerable[T] {
+	return &indexedEnumerable[T]{n: max(n, 0), at: at}
+}
+
+func (e *indexedEnumerable[T]) Len() int   { return e.n }
+func (e *indexedEnumerable[T]) At(i int) T { return e.at(i) }
+
+func (e *indexedEnumerable[T]) Enumerator() Seq[T] {
+	if e.xs != nil {
+		return &SliceSeq[T]{xs: e.xs}
+	}
+	return &indexedSeq[T]{at: e.at, n: e.n}
+}
+
+func (e *indexedEnumerable[T]) Next() (T, bool) {
+	if e.cur == nil {
+		e.cur = e.Enumerator()
+	}
+	return e.cur.Next()
+}
+
+type indexedSeq[T any] struct {
+	at   func(int) T
+	i, n int
+}
+
+func (s *indexedSeq[T]) Next() (x T, ok bool) {
+	if s.i >= s.n {
+		return
+	}
+	x, s.i = s.at(s.i), s.i+1
+	return x, true
+}
+
+func (s *indexedSeq[T]) Len() int   { return s.n - s.i }
+func (s *indexedSeq[T]) At(i int) T { return s.at(s.i + i) }
+
+// ↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓
+// Ordered Sequence Implementations
+
+// keyComparer computes the sort keys of xs once, and compares them by index
+type keyComparer[T any] func(xs []T) func(i, j int) int
+
+// OrderedSeq sorts stably on the first Next,
+// ThenBy / ThenByDescending append keys without sorting again.
+// It is Enumerable only if its source is
+type OrderedSeq[T any] interface {
+	Seq[T]
+	thenBy(key keyComparer[T]) OrderedSeq[T]
+}
+
+type orderedSeq[T any] struct {
+	src    Seq[T]
+	keys   []keyComparer[T]
+	sorted Seq[T]
+}
+
+// orderedEnumerable sorts again on every iteration
+type orderedEnumerable[T any] struct {
+	*orderedSeq[T]
+}
+
+func newOrderedSeq[T any](src Seq[T], keys []keyComparer[T]) OrderedSeq[T] {
+	s := &orderedSeq[T]{src: src, keys: keys}
+	if _, ok := unwrap(src).(Enumerable[T]); ok {
+		return orderedEnumerable[T]{s}
+	}
+	return s
+}
+
+func (s *orderedSeq[T]) Next() (T, bool) {
+	if s.sorted == nil {
+		s.sorted = s.enumerate()
+	}
+	return s.sorted.Next()
+}
+
+func (s *orderedSeq[T]) Close() error {
+	return errors.Join(Close(s.sorted), Close(s.src))
+}
+
+func (s *orderedSeq[T]) Err() error {
+	return Err(s.sorted)
+}
+
+func (s orderedEnumerable[T]) Enumerator() Seq[T] {
+	return s.enumerate()
+}
+
+func (s *orderedSeq[T]) enumerate() Seq[T] {
+	return lazy(func() Seq[T] {
+		xs, err := s.sort(enumerator(s.src))
+		if err != nil {
+			return Fail[T](err)
+		}
+		return &SliceSeq[T]{xs: xs}
+	})
+}
+
+func (s *orderedSeq[T]) thenBy(key keyComparer[T]) OrderedSeq[T] {
+	keys := make([]keyComparer[T], len(s.keys), len(s.keys)+1)
+	copy(keys, s.keys)
+	return newOrderedSeq(s.src, append(keys, key))
+}
+
+func (s *orderedSeq[T]) sort(src Seq[T]) ([]T, error) {
+	xs, err := ToSliceE(src)
+	if err != nil {
+		return nil, err
+	}
+	cmps := make([]func(i, j int) int, len(s.keys))
+	for i, key := range s.keys {
+		cmps[i] = key(xs)
+	}
+
+	idx := make([]int, len(xs))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		for _, cmp := range cmps {
+			if r := cmp(idx[a], idx[b]); r != 0 {
+				return r < 0
+			}
+		}
+		return false
+	})
+
+	ys := make([]T, len(xs))
+	for i, j := range idx {
+		ys[i] = xs[j]
+	}
+	return ys, nil
+}
+
+// ↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓
+// Grouping Sequence Implementations
+
+type Grouping[K, T any] struct {
+	Key K
+	Seq[T]
+}
@@ -1,9 +1,11 @@
 package example
 
 import (
+	"context"
 	"testing"
 
 	"github.com/goghcrow/go-linq-object/yield/iter"
+	"github.com/goghcrow/go-linq-object/yield/leaktest"
 )
 
 func TestIter(t *testing.T) {
@@ -37,20 +39,28 @@ func TestIterNext(t *testing.T) {
 }
 
 func TestInfXIter(t *testing.T) {
-	it := iter.Infinite(42)
+	defer leaktest.Check(t)()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	it := iter.InfiniteContext(ctx, 42)
 	for x := range it {
 		println(x)
-		// break
+		break
 	}
 }
 
 func TestInfIter(t *testing.T) {
-	it := iter.From(func() (int, bool) {
+	defer leaktest.Check(t)()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	it := iter.FromContext(ctx, func() (int, bool) {
 		return 42, true
 	})
 	for x := range it {
 		println(x)
-		// break
+		break
 	}
 }
 
@@ -1,5 +1,7 @@
 package iter
 
+import "context"
+
 // golang 没有迭代器,
 // 其他语言 迭代器通常可以用 for 之类语法糖来遍历, golang 中
 // 除了 slice/map, 还有 chan 可以用 for 来遍历
@@ -27,22 +29,35 @@ func Of[T any](xs ...T) Iter[T] {
 }
 
 func From[T any](f Next[T]) Iter[T] {
+	return FromContext(context.Background(), f)
+}
+
+// FromContext 在 ctx 取消时退出 goroutine 并关闭 chan, 消费方可以提前 break 而不泄漏
+func FromContext[T any](ctx context.Context, f Next[T]) Iter[T] {
 	iter := make(chan T, 1)
 	go func() {
-		for {
+		defer close(iter)
+		for ctx.Err() == nil {
 			x, has := f()
 			if !has {
 				break
 			}
-			iter <- x
+			select {
+			case iter <- x:
+			case <-ctx.Done():
+				return
+			}
 		}
-		close(iter)
 	}()
 	return iter
 }
 
 func Range(minInclusive, maxExclusive int) Iter[int] {
-	return From(func() (int, bool) {
+	return RangeContext(context.Background(), minInclusive, maxExclusive)
+}
+
+func RangeContext(ctx context.Context, minInclusive, maxExclusive int) Iter[int] {
+	return FromContext(ctx, func() (int, bool) {
 		if minInclusive < maxExclusive {
 			minInclusive++
 			return minInclusive - 1, true
@@ -52,7 +67,11 @@ func Range(minInclusive, maxExclusive int) Iter[int] {
 }
 
 func Infinite[T any](x T) Iter[T] {
-	return From(func() (T, bool) {
+	return InfiniteContext(context.Background(), x)
+}
+
+func InfiniteContext[T any](ctx context.Context, x T) Iter[T] {
+	return FromContext(ctx, func() (T, bool) {
 		return x, true
 	})
 }
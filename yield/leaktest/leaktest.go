@@ -0,0 +1,38 @@
+// Package leaktest checks that a test does not leave goroutines behind,
+//
+//	defer leaktest.Check(t)()
+//
+// goroutines stopped by a cancel exit asynchronously, so Check waits a while before failing
+package leaktest
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+const (
+	timeout  = time.Second
+	interval = 10 * time.Millisecond
+)
+
+func Check(t testing.TB) func() {
+	before := runtime.NumGoroutine()
+	return func() {
+		t.Helper()
+		deadline := time.Now().Add(timeout)
+		for {
+			n := runtime.NumGoroutine()
+			if n <= before {
+				return
+			}
+			if time.Now().After(deadline) {
+				buf := make([]byte, 1<<16)
+				buf = buf[:runtime.Stack(buf, true)]
+				t.Errorf("leaktest: %d goroutine(s) leaked\n%s", n-before, buf)
+				return
+			}
+			time.Sleep(interval)
+		}
+	}
+}
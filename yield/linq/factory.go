@@ -1,5 +1,7 @@
 package linq
 
+import "context"
+
 func Of[T any](xs ...T) Iter[T] {
 	iter := make(chan T, len(xs))
 	for _, x := range xs {
@@ -10,32 +12,41 @@ func Of[T any](xs ...T) Iter[T] {
 }
 
 func From[T any](f Next[T]) Iter[T] {
-	iter := make(chan T, internalChanCap)
-	go func() {
-		for {
+	return FromContext(context.Background(), f)
+}
+
+func FromContext[T any](ctx context.Context, f Next[T]) Iter[T] {
+	return produce(ctx, func(emit func(T) bool) {
+		for ctx.Err() == nil {
 			x, has := f()
-			if !has {
-				break
+			// f may have blocked past cancel, don't emit what it returned then
+			if !has || ctx.Err() != nil || !emit(x) {
+				return
 			}
-			iter <- x
 		}
-		close(iter)
-	}()
-	return iter
+	})
 }
 
 func OfMap[K comparable, V any](xs map[K]V) Iter[Cons[K, V]] {
+	return OfMapContext(context.Background(), xs)
+}
+
+func OfMapContext[K comparable, V any](ctx context.Context, xs map[K]V) Iter[Cons[K, V]] {
 	var ks []K
 	for k := range xs {
 		ks = append(ks, k)
 	}
-	return Select(Of(ks...), func(x K) Cons[K, V] {
+	return SelectContext(ctx, Of(ks...), func(x K) Cons[K, V] {
 		return Cons[K, V]{x, xs[x]}
 	})
 }
 
 func Range(minInclusive, maxExclusive int) Iter[int] {
-	return From(func() (int, bool) {
+	return RangeContext(context.Background(), minInclusive, maxExclusive)
+}
+
+func RangeContext(ctx context.Context, minInclusive, maxExclusive int) Iter[int] {
+	return FromContext(ctx, func() (int, bool) {
 		if minInclusive < maxExclusive {
 			minInclusive++
 			return minInclusive - 1, true
@@ -45,7 +56,12 @@ func Range(minInclusive, maxExclusive int) Iter[int] {
 }
 
 func Infinite[T any](x T) Iter[T] {
-	return From(func() (T, bool) {
+	return InfiniteContext(context.Background(), x)
+}
+
+// InfiniteContext is the way to abandon an infinite sequence without leaking
+func InfiniteContext[T any](ctx context.Context, x T) Iter[T] {
+	return FromContext(ctx, func() (T, bool) {
 		return x, true
 	})
 }
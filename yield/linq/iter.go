@@ -0,0 +1,77 @@
+package linq
+
+import (
+	"context"
+	"iter"
+)
+
+// ↓↓↓↓↓↓ range-over-func interop ↓↓↓↓↓↓
+
+// Values adapts to iter.Seq, e.g. slices.Collect(Values(xs))
+func Values[T any](xs Iter[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		xs.collect()
+		for x := range xs {
+			if !yield(x) {
+				return
+			}
+		}
+		xs.Rethrow()
+	}
+}
+
+// Enumerate adapts to iter.Seq2 with index
+func Enumerate[T any](xs Iter[T]) iter.Seq2[Index, T] {
+	return func(yield func(Index, T) bool) {
+		xs.collect()
+		i := 0
+		for x := range xs {
+			if !yield(i, x) {
+				return
+			}
+			i++
+		}
+		xs.Rethrow()
+	}
+}
+
+// Pairs adapts to iter.Seq2, e.g. maps.Collect(Pairs(OfMap(m)))
+func Pairs[K, V any](xs Iter[Cons[K, V]]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		xs.collect()
+		for x := range xs {
+			if !yield(x.Car, x.Cdr) {
+				return
+			}
+		}
+		xs.Rethrow()
+	}
+}
+
+func FromIter[T any](seq iter.Seq[T]) Iter[T] {
+	return FromIterContext(context.Background(), seq)
+}
+
+func FromIterContext[T any](ctx context.Context, seq iter.Seq[T]) Iter[T] {
+	return produce(ctx, func(emit func(T) bool) {
+		for x := range seq {
+			if !emit(x) {
+				return
+			}
+		}
+	})
+}
+
+func FromIter2[K, V any](seq iter.Seq2[K, V]) Iter[Cons[K, V]] {
+	return FromIter2Context(context.Background(), seq)
+}
+
+func FromIter2Context[K, V any](ctx context.Context, seq iter.Seq2[K, V]) Iter[Cons[K, V]] {
+	return FromIterContext(ctx, func(yield func(Cons[K, V]) bool) {
+		for k, v := range seq {
+			if !yield(Cons[K, V]{k, v}) {
+				return
+			}
+		}
+	})
+}
@@ -1,5 +1,7 @@
 package linq
 
+import "context"
+
 type (
 	Index                           = int
 	Pred[T any]                     func(T) bool
@@ -8,13 +10,25 @@ type (
 	IdxSelector[Source, Result any] func(Source, Index) Result
 )
 
+// Every operator that starts a goroutine has a ...Context variant,
+// which stops the goroutine and closes its output on cancel or deadline,
+// build the whole pipeline with the same ctx to abandon it without leaking.
+
 func SelectMany[A, R any](xs Iter[A], f func(A) Iter[R]) Iter[R] {
-	return Bind[A, R](xs, f)
+	return SelectManyContext(context.Background(), xs, f)
+}
+
+func SelectManyContext[A, R any](ctx context.Context, xs Iter[A], f func(A) Iter[R]) Iter[R] {
+	return BindContext[A, R](ctx, xs, f)
 }
 
 func SelectManyWithIndex[A, R any](xs Iter[A], f func(A, Index) Iter[R]) Iter[R] {
+	return SelectManyWithIndexContext(context.Background(), xs, f)
+}
+
+func SelectManyWithIndexContext[A, R any](ctx context.Context, xs Iter[A], f func(A, Index) Iter[R]) Iter[R] {
 	idx := 0
-	return SelectMany(xs, func(a A) (r Iter[R]) {
+	return SelectManyContext(ctx, xs, func(a A) (r Iter[R]) {
 		r, idx = f(a, idx), idx+1
 		return
 	})
@@ -22,12 +36,20 @@ func SelectManyWithIndex[A, R any](xs Iter[A], f func(A, Index) Iter[R]) Iter[R]
 
 // Select aka map
 func Select[A, R any](xs Iter[A], f Selector[A, R]) Iter[R] {
-	return SelectWithIndex(xs, func(x A, _ Index) R {
+	return SelectContext(context.Background(), xs, f)
+}
+
+func SelectContext[A, R any](ctx context.Context, xs Iter[A], f Selector[A, R]) Iter[R] {
+	return SelectWithIndexContext(ctx, xs, func(x A, _ Index) R {
 		return f(x)
 	})
 }
 
 func SelectWithIndex[A, R any](xs Iter[A], f IdxSelector[A, R]) Iter[R] {
+	return SelectWithIndexContext(context.Background(), xs, f)
+}
+
+func SelectWithIndexContext[A, R any](ctx context.Context, xs Iter[A], f IdxSelector[A, R]) Iter[R] {
 	// // e.g.
 	// i := 0
 	// return From(func() (r R, ok bool) {
@@ -39,17 +61,30 @@ func SelectWithIndex[A, R any](xs Iter[A], f IdxSelector[A, R]) Iter[R] {
 	// 	return
 	// })
 
-	return SelectManyWithIndex(xs, func(x A, i Index) Iter[R] {
+	return SelectManyWithIndexContext(ctx, xs, func(x A, i Index) Iter[R] {
 		return Return(f(x, i))
 	})
 }
 
+// First stops its own goroutine, but xs is left to the caller
 func First[A any](xs Iter[A]) (A, bool) {
-	return Take(xs, 1).Next()
+	return FirstContext(context.Background(), xs)
+}
+
+func FirstContext[A any](ctx context.Context, xs Iter[A]) (A, bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	return TakeContext(ctx, xs, 1).Next()
 }
 
 func FirstWhile[A any](xs Iter[A], p Pred[A]) (A, bool) {
-	return TakeWhile(xs, p).Next()
+	return FirstWhileContext(context.Background(), xs, p)
+}
+
+func FirstWhileContext[A any](ctx context.Context, xs Iter[A], p Pred[A]) (A, bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	return TakeWhileContext(ctx, xs, p).Next()
 }
 
 func Last[A any](xs Iter[A]) (last A, ok bool) {
@@ -57,23 +92,33 @@ func Last[A any](xs Iter[A]) (last A, ok bool) {
 }
 
 func LastWhile[A any](xs Iter[A], p Pred[A]) (last A, ok bool) {
+	xs.collect()
 	for x := range xs {
 		if p(x) {
 			ok = true
 			last = x
 		}
 	}
+	xs.Rethrow()
 	return
 }
 
 func Where[A any](xs Iter[A], p Pred[A]) Iter[A] {
-	return WhereWithIndex(xs, func(x A, _ Index) bool {
+	return WhereContext(context.Background(), xs, p)
+}
+
+func WhereContext[A any](ctx context.Context, xs Iter[A], p Pred[A]) Iter[A] {
+	return WhereWithIndexContext(ctx, xs, func(x A, _ Index) bool {
 		return p(x)
 	})
 }
 
 func WhereWithIndex[A any](xs Iter[A], p IdxPred[A]) Iter[A] {
-	return SelectManyWithIndex(xs, func(x A, i Index) Iter[A] {
+	return WhereWithIndexContext(context.Background(), xs, p)
+}
+
+func WhereWithIndexContext[A any](ctx context.Context, xs Iter[A], p IdxPred[A]) Iter[A] {
+	return SelectManyWithIndexContext(ctx, xs, func(x A, i Index) Iter[A] {
 		if p(x, i) {
 			return Return(x)
 		}
@@ -82,7 +127,11 @@ func WhereWithIndex[A any](xs Iter[A], p IdxPred[A]) Iter[A] {
 }
 
 func Take[A any](xs Iter[A], cnt int) Iter[A] {
-	return From[A](func() (x A, ok bool) {
+	return TakeContext(context.Background(), xs, cnt)
+}
+
+func TakeContext[A any](ctx context.Context, xs Iter[A], cnt int) Iter[A] {
+	return FromContext[A](ctx, func() (x A, ok bool) {
 		if cnt <= 0 {
 			return
 		}
@@ -92,17 +141,29 @@ func Take[A any](xs Iter[A], cnt int) Iter[A] {
 }
 
 func TakeWhile[A any](xs Iter[A], p Pred[A]) Iter[A] {
-	return TakeWhileWithIndex(xs, func(x A, _ Index) bool {
+	return TakeWhileContext(context.Background(), xs, p)
+}
+
+func TakeWhileContext[A any](ctx context.Context, xs Iter[A], p Pred[A]) Iter[A] {
+	return TakeWhileWithIndexContext(ctx, xs, func(x A, _ Index) bool {
 		return p(x)
 	})
 }
 
 func TakeWhileWithIndex[A any](xs Iter[A], p IdxPred[A]) Iter[A] {
-	return WhereWithIndex(xs, p)
+	return TakeWhileWithIndexContext(context.Background(), xs, p)
+}
+
+func TakeWhileWithIndexContext[A any](ctx context.Context, xs Iter[A], p IdxPred[A]) Iter[A] {
+	return WhereWithIndexContext(ctx, xs, p)
 }
 
 func Skip[A any](xs Iter[A], cnt int) Iter[A] {
-	return SelectMany(xs, func(x A) Iter[A] {
+	return SkipContext(context.Background(), xs, cnt)
+}
+
+func SkipContext[A any](ctx context.Context, xs Iter[A], cnt int) Iter[A] {
+	return SelectManyContext(ctx, xs, func(x A) Iter[A] {
 		if cnt <= 0 {
 			return Return(x)
 		}
@@ -112,13 +173,21 @@ func Skip[A any](xs Iter[A], cnt int) Iter[A] {
 }
 
 func SkipWhile[A any](xs Iter[A], p Pred[A]) Iter[A] {
-	return SkipWhileWithIndex(xs, func(x A, _ Index) bool {
+	return SkipWhileContext(context.Background(), xs, p)
+}
+
+func SkipWhileContext[A any](ctx context.Context, xs Iter[A], p Pred[A]) Iter[A] {
+	return SkipWhileWithIndexContext(ctx, xs, func(x A, _ Index) bool {
 		return p(x)
 	})
 }
 
 func SkipWhileWithIndex[A any](xs Iter[A], p IdxPred[A]) Iter[A] {
-	return SelectManyWithIndex(xs, func(x A, i Index) Iter[A] {
+	return SkipWhileWithIndexContext(context.Background(), xs, p)
+}
+
+func SkipWhileWithIndexContext[A any](ctx context.Context, xs Iter[A], p IdxPred[A]) Iter[A] {
+	return SelectManyWithIndexContext(ctx, xs, func(x A, i Index) Iter[A] {
 		if p(x, i) {
 			return nil
 		}
@@ -133,18 +202,22 @@ func Aggregate[A, B, R any](
 	selector Selector[B, R],
 ) (r R) {
 	acc := init
+	xs.collect()
 	for x := range xs {
 		acc = f(acc, x)
 	}
+	xs.Rethrow()
 	return selector(acc)
 }
 
 // Fold Aggregate with init
 func Fold[A, R any](xs Iter[A], init R, f func(acc R, cur A) R) (acc R) {
 	acc = init
+	xs.collect()
 	for x := range xs {
 		acc = f(acc, x)
 	}
+	xs.Rethrow()
 	return
 }
 
@@ -154,9 +227,11 @@ func Reduce[A any](xs Iter[A], f func(acc A, cur A) A) (r A, ok bool) {
 	if !ok {
 		return
 	}
+	xs.collect()
 	for x := range xs {
 		r = f(r, x)
 	}
+	xs.Rethrow()
 	ok = true
 	return
 }
@@ -196,23 +271,30 @@ func Any[A any](xs Iter[A], p Pred[A]) (r bool) {
 }
 
 func Append[A any](xs Iter[A], a A) Iter[A] {
+	return AppendContext(context.Background(), xs, a)
+}
+
+func AppendContext[A any](ctx context.Context, xs Iter[A], a A) Iter[A] {
 	end := false
-	return From(func() (x A, ok bool) {
+	return FromContext(ctx, func() (x A, ok bool) {
 		if end {
 			return
 		}
 		x, ok = xs.Next()
 		if !ok {
 			end = true
-			return a, true
+			// xs may have ended because of cancel
+			return a, ctx.Err() == nil
 		}
 		return
 	})
 }
 
 func Count[A any](xs Iter[A]) (cnt int) {
+	xs.collect()
 	for _ = range xs {
 		cnt++
 	}
+	xs.Rethrow()
 	return
 }
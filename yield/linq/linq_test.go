@@ -1,10 +1,19 @@
 package linq
 
 import (
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"os/exec"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/goghcrow/go-linq-object/yield/leaktest"
 )
 
 // tests ref
@@ -439,3 +448,167 @@ func TestCrossJoin(t *testing.T) {
 		{"c", 1}, T{"c", 2}, T{"c", 3},
 	})
 }
+
+func TestValues(t *testing.T) {
+	assertEqual(t, slices.Collect(Values(Of(1, 2, 3))), []int{1, 2, 3})
+
+	var ys []string
+	for i, x := range Enumerate(Of("a", "b")) {
+		ys = append(ys, strconv.Itoa(i)+x)
+	}
+	assertEqual(t, ys, []string{"0a", "1b"})
+
+	m := map[string]int{"a": 1, "b": 2}
+	assertEqual(t, maps.Collect(Pairs(OfMap(m))), m)
+}
+
+func TestFromIter(t *testing.T) {
+	xs := FromIter(slices.Values([]int{1, 2, 3, 4}))
+	assertEqual(t, Select(xs, square).ToSlice(), []int{1, 4, 9, 16})
+
+	m := map[string]int{"a": 1}
+	assertEqual(t, FromIter2(maps.All(m)).ToSlice(), []Cons[string, int]{{"a", 1}})
+}
+
+func TestContext(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	xs := SkipContext(ctx, WhereContext(ctx, SelectContext(ctx, InfiniteContext(ctx, 2), square), isEven), 1)
+	ys := SelectManyContext(ctx, RangeContext(ctx, 0, 10), func(int) Iter[int] {
+		return AppendContext(ctx, TakeContext(ctx, InfiniteContext(ctx, 2), 3), 3)
+	})
+	x, _ := xs.Next()
+	assertEqual(t, x, 4)
+	assertEqual(t, TakeContext(ctx, ys, 4).ToSlice(), []int{2, 2, 2, 3})
+
+	// abandoned pipelines are closed on cancel
+	cancel()
+	for range xs {
+	}
+	for range ys {
+	}
+	_, ok := ys.Next()
+	assertEqual(t, ok, false)
+}
+
+func TestBindCancel(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	// the inner Iters don't see ctx
+	ctx, cancel := context.WithCancel(context.Background())
+	ys := BindContext(ctx, Of(0, 1, 2), func(int) Iter[int] { return Range(0, 100) })
+	x, _ := ys.Next()
+	assertEqual(t, x, 0)
+	cancel()
+	for range ys {
+	}
+}
+
+func TestContextDeadline(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	n := 0
+	for range SelectContext(ctx, InfiniteContext(ctx, 1), square) {
+		n++
+	}
+	assertEqual(t, n > 0, true)
+}
+
+func TestFirstNoLeak(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	x, _ := FirstWhile(Of(1, 2, 3, 4, 5), gt(1))
+	assertEqual(t, x, 2)
+}
+
+func TestPanic(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	boom := errors.New("boom")
+	explode := func(x int) int {
+		if x == 3 {
+			panic(boom)
+		}
+		return x
+	}
+	recovered := func(f func()) (p *PanicError) {
+		defer func() { p, _ = recover().(*PanicError) }()
+		f()
+		return
+	}
+
+	p := recovered(func() { Select(Range(0, 5), explode).ToSlice() })
+	assertEqual(t, p != nil && p.Value == boom, true)
+	assertEqual(t, errors.Is(p, boom), true)
+	assertEqual(t, strings.Contains(string(p.Stack), "TestPanic"), true)
+
+	// through a pipeline of goroutines, Next
+	xs := Skip(Where(Select(Range(0, 5), explode), Const[int](true)), 1)
+	x, _ := xs.Next()
+	assertEqual(t, x, 1)
+	x, _ = xs.Next()
+	assertEqual(t, x, 2)
+	p = recovered(func() { xs.Next() })
+	assertEqual(t, p != nil && p.Value == boom, true)
+
+	// inner Iters
+	p = recovered(func() {
+		SelectMany(Range(0, 2), func(int) Iter[int] {
+			return Select(Range(0, 5), explode)
+		}).ToSlice()
+	})
+	assertEqual(t, p != nil && p.Value == boom, true)
+
+	// range-over-func
+	p = recovered(func() {
+		for range Values(Select(Range(0, 5), explode)) {
+		}
+	})
+	assertEqual(t, p != nil && p.Value == boom, true)
+
+	p = recovered(func() { FromIter(func(func(int) bool) { panic("iter") }).Next() })
+	assertEqual(t, p != nil && p.Value == "iter", true)
+	assertEqual(t, recovered(func() { Range(0, 5).ToSlice() }) == nil, true)
+}
+
+// a plain range loop can't re-raise, the producer crashes the program instead
+func TestPanicPlainRange(t *testing.T) {
+	if os.Getenv("PLAIN_RANGE") == "1" {
+		for range Select(Range(0, 5), func(x int) int {
+			if x == 3 {
+				panic("boom")
+			}
+			return x
+		}) {
+		}
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestPanicPlainRange$")
+	cmd.Env = append(os.Environ(), "PLAIN_RANGE=1")
+	out, err := cmd.CombinedOutput()
+	assertEqual(t, err != nil, true)
+	assertEqual(t, strings.Contains(string(out), "panic: boom"), true)
+}
+
+func TestQuery(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	// Take leaves the upstream goroutines blocked, cancel them at the end
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := AsQueryContext(ctx, RangeContext(ctx, 0, 10))
+	assertEqual(t, q.Where(isEven).Skip(1).Take(3).ToSlice(), []int{2, 4, 6})
+	ys := AsQueryContext(ctx, SelectContext(ctx, AsQueryContext(ctx, RangeContext(ctx, 0, 10)).Where(isEven).Iter, square))
+	assertEqual(t, ys.Take(2).Append(0).ToSlice(), []int{0, 4, 0})
+
+	// no even number ever, abandoned on deadline,
+	// Append must not emit 42 once Where ended because of it
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	x, ok := AsQueryContext(ctx, InfiniteContext(ctx, 1)).SkipWhile(lt(1)).Where(isEven).Append(42).First()
+	assertEqual(t, x, 0)
+	assertEqual(t, ok, false)
+}
@@ -1,5 +1,7 @@
 package linq
 
+import "context"
+
 // https://groups.google.com/g/elm-discuss/c/rAfKkv2w1GU
 //
 //                   (a -> b) -> a -> b                Names: apply, <|, $
@@ -23,18 +25,58 @@ func Unit[T any](x T) Iter[T] {
 
 // Bind aka flatMap
 func Bind[A, R any](xs Iter[A], f func(A) Iter[R]) Iter[R] {
-	iter := make(chan R, internalChanCap)
-	go func() {
-		for x := range xs {
-			if ys := f(x); ys != nil { // DON'T BLOCK
-				for y := range ys {
-					iter <- y
+	return BindContext(context.Background(), xs, f)
+}
+
+// BindContext stops its goroutine and closes the output on cancel
+func BindContext[A, R any](ctx context.Context, xs Iter[A], f func(A) Iter[R]) Iter[R] {
+	return produce(ctx, func(emit func(R) bool) {
+		xs.collect()
+		for {
+			var x A
+			var ok bool
+			select {
+			case x, ok = <-xs:
+			case <-ctx.Done():
+				return
+			}
+			if !ok {
+				break
+			}
+			ys := f(x)
+			if ys == nil { // DON'T BLOCK
+				continue
+			}
+			ys.collect()
+			for {
+				var y R
+				select {
+				case y, ok = <-ys:
+				case <-ctx.Done():
+					go drain(ys)
+					return
+				}
+				if !ok {
+					break
+				}
+				if !emit(y) {
+					go drain(ys)
+					return
 				}
 			}
+			ys.Rethrow()
 		}
-		close(iter)
-	}()
-	return iter
+		xs.Rethrow()
+	})
+}
+
+// drain unblocks the producer of an abandoned inner Iter,
+// which may not share the cancelled ctx
+func drain[T any](xs Iter[T]) {
+	xs.collect()
+	for range xs {
+	}
+	xs.take()
 }
 
 // ↓↓↓↓↓↓ Alias ↓↓↓↓↓↓
@@ -42,3 +84,7 @@ func Bind[A, R any](xs Iter[A], f func(A) Iter[R]) Iter[R] {
 func Return[T any](x T) Iter[T] { return Unit(x) }
 
 func FlatMap[A, R any](xs Iter[A], f func(A) Iter[R]) Iter[R] { return Bind(xs, f) }
+
+func FlatMapContext[A, R any](ctx context.Context, xs Iter[A], f func(A) Iter[R]) Iter[R] {
+	return BindContext(ctx, xs, f)
+}
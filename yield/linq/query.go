@@ -0,0 +1,62 @@
+package linq
+
+import (
+	"context"
+	"iter"
+)
+
+// ↓↓↓↓↓↓ Fluent Query ↓↓↓↓↓↓
+// Same as the root Query, the type-changing operators stay free functions, e.g.
+//
+//	AsQuery(Select(AsQuery(xs).Where(p).Skip(1).Iter, f)).Take(2).ToSlice()
+
+// Query builds every stage with its ctx, see AsQueryContext
+type Query[T any] struct {
+	Iter[T]
+	ctx context.Context
+}
+
+func AsQuery[T any](xs Iter[T]) Query[T] {
+	return AsQueryContext(context.Background(), xs)
+}
+
+// AsQueryContext stops the goroutines of all the following stages on cancel
+func AsQueryContext[T any](ctx context.Context, xs Iter[T]) Query[T] {
+	return Query[T]{xs, ctx}
+}
+
+func (q Query[T]) with(xs Iter[T]) Query[T] { return Query[T]{xs, q.ctx} }
+
+// ↓↓↓↓↓↓ Operators ↓↓↓↓↓↓
+
+func (q Query[T]) Where(p Pred[T]) Query[T] { return q.with(WhereContext(q.ctx, q.Iter, p)) }
+func (q Query[T]) WhereWithIndex(p IdxPred[T]) Query[T] {
+	return q.with(WhereWithIndexContext(q.ctx, q.Iter, p))
+}
+func (q Query[T]) Take(cnt int) Query[T]        { return q.with(TakeContext(q.ctx, q.Iter, cnt)) }
+func (q Query[T]) TakeWhile(p Pred[T]) Query[T] { return q.with(TakeWhileContext(q.ctx, q.Iter, p)) }
+func (q Query[T]) TakeWhileWithIndex(p IdxPred[T]) Query[T] {
+	return q.with(TakeWhileWithIndexContext(q.ctx, q.Iter, p))
+}
+func (q Query[T]) Skip(cnt int) Query[T]        { return q.with(SkipContext(q.ctx, q.Iter, cnt)) }
+func (q Query[T]) SkipWhile(p Pred[T]) Query[T] { return q.with(SkipWhileContext(q.ctx, q.Iter, p)) }
+func (q Query[T]) SkipWhileWithIndex(p IdxPred[T]) Query[T] {
+	return q.with(SkipWhileWithIndexContext(q.ctx, q.Iter, p))
+}
+func (q Query[T]) Append(x T) Query[T] { return q.with(AppendContext(q.ctx, q.Iter, x)) }
+
+// ↓↓↓↓↓↓ Terminals ↓↓↓↓↓↓
+
+func (q Query[T]) First() (T, bool)               { return FirstContext(q.ctx, q.Iter) }
+func (q Query[T]) FirstWhile(p Pred[T]) (T, bool) { return FirstWhileContext(q.ctx, q.Iter, p) }
+func (q Query[T]) Last() (T, bool)                { return Last(q.Iter) }
+func (q Query[T]) LastWhile(p Pred[T]) (T, bool)  { return LastWhile(q.Iter, p) }
+func (q Query[T]) All(p Pred[T]) bool             { return All(q.Iter, p) }
+func (q Query[T]) Any(p Pred[T]) bool             { return Any(q.Iter, p) }
+func (q Query[T]) AnyElem() bool                  { return AnyElem(q.Iter) }
+func (q Query[T]) Count() int                     { return Count(q.Iter) }
+func (q Query[T]) Reduce(f func(acc, cur T) T) (T, bool) {
+	return Reduce(q.Iter, f)
+}
+func (q Query[T]) Values() iter.Seq[T]            { return Values(q.Iter) }
+func (q Query[T]) Enumerate() iter.Seq2[Index, T] { return Enumerate(q.Iter) }
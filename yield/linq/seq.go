@@ -1,5 +1,13 @@
 package linq
 
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
 // Sequence
 
 type (
@@ -8,13 +16,138 @@ type (
 )
 
 func (i Iter[T]) Next() (T, bool) {
+	i.collect()
 	x, ok := <-i
+	if !ok {
+		i.Rethrow()
+	}
 	return x, ok
 }
 
 func (i Iter[T]) ToSlice() (ys []T) {
+	i.collect()
 	for x := range i {
 		ys = append(ys, x)
 	}
+	i.Rethrow()
 	return
 }
+
+// Panic
+//
+// A panic in a producer goroutine is recovered and the output is closed,
+// Next, ToSlice, Values / Enumerate / Pairs and the operators reading the Iter
+// re-raise it as *PanicError on the consumer after the elements before it.
+// A plain range loop can't see it, so the producer re-panics instead and
+// the program crashes as if nothing had recovered it. If the panic comes
+// before the loop took a second element, the producer can't tell the loop
+// from a consumer that hasn't started yet, and waits for one or for ctx.
+
+type PanicError struct {
+	Value any
+	Stack []byte // stack of the producer goroutine where it panicked
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%v\n\n%s", e.Value, e.Stack)
+}
+
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// pipes Iter[T] -> *pipe, while the producer goroutine runs.
+// Iter is a bare channel, which can't carry the panic itself
+var pipes sync.Map
+
+type pipe struct {
+	collected atomic.Bool
+	wake      chan struct{} // closed by the first collector
+	p         atomic.Pointer[PanicError]
+	taken     chan struct{} // closed once p is re-raised
+}
+
+// Rethrow re-raises the panic of the producer of i, if any
+func (i Iter[T]) Rethrow() {
+	if p := i.take(); p != nil {
+		panic(p)
+	}
+}
+
+// collect tells the producer of i the consumer re-raises its panic,
+// must be called before the first receive
+func (i Iter[T]) collect() {
+	if v, ok := pipes.Load(i); ok {
+		if p := v.(*pipe); !p.collected.Load() && p.collected.CompareAndSwap(false, true) {
+			close(p.wake)
+		}
+	}
+}
+
+func (i Iter[T]) take() *PanicError {
+	v, ok := pipes.Load(i)
+	if !ok {
+		return nil
+	}
+	p := v.(*pipe)
+	pe := p.p.Swap(nil)
+	if pe != nil {
+		close(p.taken)
+	}
+	return pe
+}
+
+// produce runs body in a goroutine, emit blocks until the consumer receives x
+// or ctx is done
+func produce[T any](ctx context.Context, body func(emit func(x T) bool)) Iter[T] {
+	ch := make(chan T, internalChanCap)
+	p := &pipe{wake: make(chan struct{}), taken: make(chan struct{})}
+	pipes.Store(Iter[T](ch), p)
+	go func() {
+		sent := 0
+		defer func() { finish(ctx, p, ch, sent, recover()) }()
+		body(func(x T) bool {
+			select {
+			case ch <- x:
+				sent++
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return ch
+}
+
+func finish[T any](ctx context.Context, p *pipe, ch chan T, sent int, r any) {
+	defer pipes.Delete(Iter[T](ch))
+	if r == nil {
+		close(ch)
+		return
+	}
+	pe, ok := r.(*PanicError)
+	if !ok {
+		pe = &PanicError{Value: r, Stack: debug.Stack()}
+	}
+	if !p.collected.Load() {
+		// a collector marks itself before its first receive, and the send
+		// after a full buffer completes only after a receive,
+		// so nobody collects: a plain range loop
+		if sent > cap(ch) {
+			panic(pe)
+		}
+		select {
+		case <-p.wake:
+		case <-ctx.Done():
+			close(ch)
+			return
+		}
+	}
+	p.p.Store(pe)
+	close(ch)
+	select {
+	case <-p.taken:
+	case <-ctx.Done():
+	}
+}